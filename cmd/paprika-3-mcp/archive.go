@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+// credentials are the flags every command that talks to Paprika needs
+type credentials struct {
+	username string
+	password string
+	baseURL  string
+}
+
+func credentialFlags(fs *flag.FlagSet) *credentials {
+	var c credentials
+	fs.StringVar(&c.username, "username", os.Getenv("PAPRIKA_USERNAME"), "Paprika 3 username (email)")
+	fs.StringVar(&c.password, "password", os.Getenv("PAPRIKA_PASSWORD"), "Paprika 3 password")
+	fs.StringVar(&c.baseURL, "base-url", os.Getenv("PAPRIKA_BASE_URL"), "Paprika API base URL (defaults to https://paprikaapp.com)")
+	return &c
+}
+
+// client logs in to Paprika, logging warnings to stderr
+func (c *credentials) client() (*paprika.Client, error) {
+	if c.username == "" || c.password == "" {
+		return nil, fmt.Errorf("Paprika credentials required. Set PAPRIKA_USERNAME and PAPRIKA_PASSWORD environment variables or provide --username and --password flags")
+	}
+	return paprika.NewClient(paprika.NewClientOptions{
+		Username: c.username,
+		Password: c.password,
+		BaseURL:  c.baseURL,
+		Version:  version,
+		Logger:   slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})),
+	})
+}
+
+// runExport implements "paprika-3-mcp export", which writes recipes to a .paprikarecipes archive
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	creds := credentialFlags(fs)
+	uids := fs.String("uids", "", "Comma-separated UIDs of the recipes to export (defaults to every recipe that isn't in the trash)")
+	noPhotos := fs.Bool("no-photos", false, "Don't embed the recipes' photos")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: paprika-3-mcp export [flags] <file.paprikarecipes>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	client, err := creds.client()
+	if err != nil {
+		return err
+	}
+
+	opts := paprika.ExportOptions{Photos: !*noPhotos}
+	for _, uid := range strings.Split(*uids, ",") {
+		if uid = strings.TrimSpace(uid); uid != "" {
+			opts.UIDs = append(opts.UIDs, uid)
+		}
+	}
+	recipes, err := client.ExportRecipes(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	if err := paprika.WriteArchiveFile(fs.Arg(0), recipes); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d recipes to %s\n", len(recipes), fs.Arg(0))
+	return nil
+}
+
+// runImport implements "paprika-3-mcp import", which saves the recipes of a .paprikarecipes archive to Paprika
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	creds := credentialFlags(fs)
+	overwrite := fs.Bool("overwrite", false, "Replace recipes that already exist instead of skipping them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: paprika-3-mcp import [flags] <file.paprikarecipes>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	recipes, err := paprika.ReadArchiveFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	client, err := creds.client()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.ImportRecipes(context.Background(), recipes, paprika.ImportOptions{Overwrite: *overwrite})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d recipes\n", len(result.Saved))
+	for _, name := range result.Skipped {
+		fmt.Printf("Skipped %s, which already exists\n", name)
+	}
+	if result.PhotosSkipped > 0 {
+		fmt.Printf("%d photos were not imported; add them in the Paprika app\n", result.PhotosSkipped)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+// runSyncCooklang implements "paprika-3-mcp sync-cooklang", which saves a directory of .cook files to Paprika
+func runSyncCooklang(args []string) error {
+	fs := flag.NewFlagSet("sync-cooklang", flag.ExitOnError)
+	creds := credentialFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print what would change without saving anything")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: paprika-3-mcp sync-cooklang [flags] <directory>")
+		fmt.Fprintln(fs.Output(), "Creates or updates a Paprika recipe for every .cook file in the directory. Recipes are matched by the uid in their metadata, or else by name.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	recipes, err := paprika.ReadCooklangDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	client, err := creds.client()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.SyncRecipes(context.Background(), recipes, paprika.SyncOptions{DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range result.Created {
+		fmt.Printf("created  %s\n", name)
+	}
+	for _, name := range result.Updated {
+		fmt.Printf("updated  %s\n", name)
+	}
+	fmt.Printf("%d created, %d updated, %d unchanged\n", len(result.Created), len(result.Updated), len(result.Unchanged))
+	if *dryRun {
+		fmt.Println("Dry run: nothing was saved")
+	}
+	return nil
+}
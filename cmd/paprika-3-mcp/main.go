@@ -28,9 +28,43 @@ func getLogFilePath() string {
 	}
 }
 
+// defaultCacheDir returns the directory recipes are cached in unless overridden
+func defaultCacheDir() string {
+	if dir := os.Getenv("PAPRIKA_CACHE_DIR"); dir != "" {
+		return dir
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		// fallback to /tmp if the OS doesn't define a cache directory
+		return filepath.Join(os.TempDir(), "paprika-3-mcp")
+	}
+	return filepath.Join(dir, "paprika-3-mcp")
+}
+
+// commands are the subcommands that run instead of the MCP server
+var commands = map[string]func(args []string) error{
+	"export":        runExport,
+	"import":        runImport,
+	"sync-cooklang": runSyncCooklang,
+	"sync-vault":    runSyncVault,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	username := flag.String("username", os.Getenv("PAPRIKA_USERNAME"), "Paprika 3 username (email)")
 	password := flag.String("password", os.Getenv("PAPRIKA_PASSWORD"), "Paprika 3 password")
+	baseURL := flag.String("base-url", os.Getenv("PAPRIKA_BASE_URL"), "Paprika API base URL (defaults to https://paprikaapp.com)")
+	cacheDir := flag.String("cache-dir", defaultCacheDir(), "Directory to cache recipes in, so they are available offline; set to an empty string to disable caching")
 	showVersion := flag.Bool("version", false, "Print version and exit")
 	flag.Parse()
 
@@ -61,6 +95,8 @@ func main() {
 		Version:  version,
 		Username: *username,
 		Password: *password,
+		BaseURL:  *baseURL,
+		CacheDir: *cacheDir,
 		Logger:   logger,
 	})
 	if err != nil {
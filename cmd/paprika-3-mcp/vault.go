@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+// runSyncVault implements "paprika-3-mcp sync-vault", which syncs recipes both ways with a folder of Markdown notes
+func runSyncVault(args []string) error {
+	fs := flag.NewFlagSet("sync-vault", flag.ExitOnError)
+	creds := credentialFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print what would change without writing notes or saving recipes")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: paprika-3-mcp sync-vault [flags] <directory>")
+		fmt.Fprintln(fs.Output(), "Mirrors every recipe into the directory as a Markdown note, and saves notes edited or added there back to Paprika. Recipes edited on both sides since the last sync are reported as conflicts and left alone.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	client, err := creds.client()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.SyncVault(context.Background(), fs.Arg(0), paprika.VaultOptions{DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range result.Pulled {
+		fmt.Printf("pulled   %s\n", name)
+	}
+	for _, name := range result.Pushed {
+		fmt.Printf("pushed   %s\n", name)
+	}
+	for _, name := range result.Removed {
+		fmt.Printf("removed  %s\n", name)
+	}
+	for _, conflict := range result.Conflicts {
+		fmt.Printf("conflict %s (%s): %s\n", conflict.Name, conflict.Path, conflict.Reason)
+	}
+	fmt.Printf("%d pulled, %d pushed, %d removed, %d unchanged, %d conflicts\n",
+		len(result.Pulled), len(result.Pushed), len(result.Removed), result.Unchanged, len(result.Conflicts))
+	if *dryRun {
+		fmt.Println("Dry run: nothing was written or saved")
+	}
+	if len(result.Conflicts) > 0 {
+		return fmt.Errorf("%d notes were not synced; resolve the conflicts and sync again", len(result.Conflicts))
+	}
+	return nil
+}
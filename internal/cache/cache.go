@@ -0,0 +1,146 @@
+// Package cache persists the recipe library to disk, so the server can start with the
+// last known recipes and keep serving them while Paprika is unreachable.
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+// Entry is a cached recipe along with the hash it was listed with
+type Entry struct {
+	Hash string `json:"hash"`
+	// Modified is when the recipe was last changed. Paprika doesn't track this, so it is recorded locally.
+	Modified time.Time      `json:"modified"`
+	Recipe   paprika.Recipe `json:"recipe"`
+}
+
+// Store is a directory holding one JSON file per recipe, named after its UID, and the categories
+type Store struct {
+	dir string
+}
+
+// Open returns a store backed by dir, creating the directory if needed
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "recipes"), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the directory the store is backed by
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// validUID matches the UIDs Paprika gives recipes, so a malformed UID can't name a file outside the cache
+var validUID = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+func (s *Store) recipePath(uid string) (string, error) {
+	if !validUID.MatchString(uid) {
+		return "", fmt.Errorf("invalid recipe uid %q", uid)
+	}
+	return filepath.Join(s.dir, "recipes", strings.ToUpper(uid)+".json"), nil
+}
+
+// Recipes returns every cached recipe, including recipes in the trash.
+// Files that can't be decoded are skipped, so a corrupt entry is simply fetched again.
+func (s *Store) Recipes() ([]Entry, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "recipes", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(paths))
+	for _, path := range paths {
+		var entry Entry
+		if err := readJSON(path, &entry); err != nil || entry.Recipe.UID == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// PutRecipe stores a recipe under the given hash, replacing any earlier version
+func (s *Store) PutRecipe(recipe *paprika.Recipe, hash string, modified time.Time) error {
+	path, err := s.recipePath(recipe.UID)
+	if err != nil {
+		return err
+	}
+	return writeJSON(path, Entry{Hash: hash, Modified: modified, Recipe: *recipe})
+}
+
+// RemoveRecipe deletes a recipe from the cache. Removing a recipe that isn't cached is not an error.
+func (s *Store) RemoveRecipe(uid string) error {
+	path, err := s.recipePath(uid)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Categories returns the cached categories, or nil if none were cached yet
+func (s *Store) Categories() (paprika.Categories, error) {
+	var categories paprika.Categories
+	if err := readJSON(filepath.Join(s.dir, "categories.json"), &categories); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// PutCategories replaces the cached categories
+func (s *Store) PutCategories(categories paprika.Categories) error {
+	return writeJSON(filepath.Join(s.dir, "categories.json"), categories)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// writeJSON writes v to a temporary file first, so readers never see a partially written file
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,85 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/cache"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRecipes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	store, err := cache.Open(dir)
+	require.NoError(t, err)
+
+	entries, err := store.Recipes()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	modified := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	require.NoError(t, store.PutRecipe(&paprika.Recipe{UID: "a", Name: "Soup"}, "v1", modified))
+	require.NoError(t, store.PutRecipe(&paprika.Recipe{UID: "B", Name: "Salad", InTrash: true}, "v1", modified))
+	require.NoError(t, store.PutRecipe(&paprika.Recipe{UID: "A", Name: "Stew"}, "v2", modified.Add(time.Hour)))
+
+	// a reopened store sees the same recipes
+	store, err = cache.Open(dir)
+	require.NoError(t, err)
+	entries, err = store.Recipes()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, cache.Entry{Hash: "v2", Modified: modified.Add(time.Hour), Recipe: paprika.Recipe{UID: "A", Name: "Stew"}}, entries[0])
+	assert.True(t, entries[1].Recipe.InTrash)
+
+	require.NoError(t, store.RemoveRecipe("b"))
+	require.NoError(t, store.RemoveRecipe("missing"))
+	entries, err = store.Recipes()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "A", entries[0].Recipe.UID)
+}
+
+func TestStoreSkipsCorruptRecipes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := cache.Open(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.PutRecipe(&paprika.Recipe{UID: "A", Name: "Soup"}, "v1", time.Now()))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "recipes", "B.json"), []byte("{not json"), 0o600))
+
+	entries, err := store.Recipes()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "A", entries[0].Recipe.UID)
+}
+
+func TestStoreRejectsInvalidUIDs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	store, err := cache.Open(dir)
+	require.NoError(t, err)
+
+	for _, uid := range []string{"", "../escape", "a/b", "a.b"} {
+		assert.Error(t, store.PutRecipe(&paprika.Recipe{UID: uid, Name: "Soup"}, "v1", time.Now()), uid)
+		assert.Error(t, store.RemoveRecipe(uid), uid)
+	}
+	assert.NoFileExists(t, filepath.Join(dir, "escape.json"))
+}
+
+func TestStoreCategories(t *testing.T) {
+	store, err := cache.Open(t.TempDir())
+	require.NoError(t, err)
+
+	categories, err := store.Categories()
+	require.NoError(t, err)
+	assert.Nil(t, categories)
+
+	want := paprika.Categories{{UID: "DINNER", Name: "Dinner"}}
+	require.NoError(t, store.PutCategories(want))
+	categories, err = store.Categories()
+	require.NoError(t, err)
+	assert.Equal(t, want, categories)
+}
@@ -0,0 +1,124 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+func (s *Server) archiveTools() []server.ServerTool {
+	exportRecipesTool := mcp.NewTool("export_recipes",
+		mcp.WithDescription("Export recipes from the Paprika 3 app to a .paprikarecipes archive file, the format the Paprika apps import and export. Photos are embedded in the archive."),
+		mcp.WithString("path", mcp.Description("The absolute path of the archive file to write; .paprikarecipes is appended if missing"), mcp.Required()),
+		mcp.WithArray("uids", mcp.Description("The UIDs of the recipes to export; defaults to every recipe that isn't in the trash"), mcp.Items(map[string]interface{}{"type": "string"})),
+		mcp.WithBoolean("photos", mcp.Description("Embed the recipes' photos"), mcp.DefaultBool(true)),
+	)
+
+	importRecipesTool := mcp.NewTool("import_recipes",
+		mcp.WithDescription("Import the recipes of a .paprikarecipes archive file into the Paprika 3 app, keeping their UIDs and created dates. Categories are matched by name and created if needed. Embedded photos are not imported."),
+		mcp.WithString("path", mcp.Description("The absolute path of the archive file to read"), mcp.Required()),
+		mcp.WithBoolean("overwrite", mcp.Description("Replace recipes that already exist in the app instead of skipping them"), mcp.DefaultBool(false)),
+	)
+
+	return []server.ServerTool{
+		{Tool: exportRecipesTool, Handler: s.exportRecipes},
+		{Tool: importRecipesTool, Handler: s.importRecipes},
+	}
+}
+
+// archivePath returns the path argument with the archive extension added if it is missing
+func archivePath(args map[string]interface{}) (string, error) {
+	path := stringArgument(args, "path")
+	if path == "" {
+		return "", errors.New("path is required")
+	}
+	if !strings.EqualFold(filepath.Ext(path), paprika.ArchiveExtension) {
+		path += paprika.ArchiveExtension
+	}
+	return path, nil
+}
+
+func (s *Server) exportRecipes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start := time.Now()
+	path, err := archivePath(req.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	uids, err := stringSliceArgument(req.Params.Arguments, "uids")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	recipes, err := s.paprika3.ExportRecipes(ctx, paprika.ExportOptions{
+		UIDs:   uids,
+		Photos: boolArgument(req.Params.Arguments, "photos", true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := paprika.WriteArchiveFile(path, recipes); err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start)
+	s.logger.Info("Exported recipes", "count", len(recipes), "path", path, "duration", duration)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Exported %d recipes to %s", len(recipes), path)), nil
+}
+
+func (s *Server) importRecipes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start := time.Now()
+	path := stringArgument(req.Params.Arguments, "path")
+	if path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	recipes, err := paprika.ReadArchiveFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	result, err := s.paprika3.ImportRecipes(ctx, recipes, paprika.ImportOptions{
+		Overwrite: boolArgument(req.Params.Arguments, "overwrite", false),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var c changes
+	for _, recipe := range result.Saved {
+		added, updated, removed := s.storeRecipe(recipe, recipe.Hash, time.Now())
+		c.record(recipe.UID, added, updated, removed)
+	}
+	s.notifyResourceChanges(c)
+
+	duration := time.Since(start)
+	s.logger.Info("Imported recipes", "count", len(result.Saved), "skipped", len(result.Skipped), "path", path, "duration", duration)
+
+	return mcp.NewToolResultText(importSummary(result)), nil
+}
+
+// importSummary describes the outcome of an import
+func importSummary(result *paprika.ImportResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Imported %d recipes\n", len(result.Saved)))
+	if len(result.Skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("Skipped %d recipes that already exist: %s\n", len(result.Skipped), strings.Join(result.Skipped, ", ")))
+	}
+	if result.PhotosSkipped > 0 {
+		sb.WriteString(fmt.Sprintf("%d photos were not imported; add them in the Paprika app\n", result.PhotosSkipped))
+	}
+	return sb.String()
+}
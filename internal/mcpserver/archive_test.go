@@ -0,0 +1,46 @@
+package mcpserver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAndImportRecipes(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	fake.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup", Categories: []string{"DINNER"}})
+	fake.PutRecipe(paprika.Recipe{UID: "STEW", Name: "Stew"})
+
+	path := filepath.Join(t.TempDir(), "library")
+	texts := callTool(t, s, "export_recipes", map[string]interface{}{"path": path, "uids": []string{"SOUP"}})
+	require.Len(t, texts, 1)
+	assert.Equal(t, "Exported 1 recipes to "+path+".paprikarecipes", texts[0])
+
+	recipes, err := paprika.ReadArchiveFile(path + ".paprikarecipes")
+	require.NoError(t, err)
+	require.Len(t, recipes, 1)
+	assert.Equal(t, []string{"Dinner"}, recipes[0].Categories)
+
+	other, otherFake := newTestServer(t)
+	otherFake.PutRecipe(paprika.Recipe{UID: "STEW", Name: "Stew"})
+	require.NoError(t, paprika.WriteArchiveFile(path+".paprikarecipes", append(recipes, paprika.ArchiveRecipe{Recipe: paprika.Recipe{UID: "STEW", Name: "Stew"}})))
+
+	texts = callTool(t, other, "import_recipes", map[string]interface{}{"path": path + ".paprikarecipes"})
+	require.Len(t, texts, 1)
+	assert.Equal(t, "Imported 1 recipes\nSkipped 1 recipes that already exist: Stew\n", texts[0])
+
+	soup, ok := otherFake.Recipe("SOUP")
+	require.True(t, ok)
+	assert.Len(t, soup.Categories, 1)
+	assert.Contains(t, listRecipeResources(t, other), "paprika://recipes/SOUP")
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "import_recipes",
+		"arguments": map[string]interface{}{"path": filepath.Join(t.TempDir(), "missing.paprikarecipes")},
+	})
+	require.NotNil(t, resp.Error)
+}
@@ -0,0 +1,81 @@
+package mcpserver
+
+import (
+	"fmt"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+// stringSliceArgument returns the items of an optional array-of-strings tool argument
+func stringSliceArgument(args map[string]interface{}, name string) ([]string, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of strings", name)
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array of strings", name)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// stringArgument returns an optional string tool argument, or an empty string if it wasn't provided
+func stringArgument(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// boolArgument returns an optional boolean tool argument, or def if it wasn't provided
+func boolArgument(args map[string]interface{}, name string, def bool) bool {
+	value, ok := args[name].(bool)
+	if !ok {
+		return def
+	}
+	return value
+}
+
+// intArgument returns an optional integer tool argument, or def if it wasn't provided
+func intArgument(args map[string]interface{}, name string, def int) int {
+	// JSON numbers are decoded as float64
+	value, ok := args[name].(float64)
+	if !ok {
+		return def
+	}
+	return int(value)
+}
+
+// floatArgument returns an optional number tool argument, or def if it wasn't provided
+func floatArgument(args map[string]interface{}, name string, def float64) float64 {
+	value, ok := args[name].(float64)
+	if !ok {
+		return def
+	}
+	return value
+}
+
+// createdArgument returns the optional created date of a recipe in the layout Paprika uses, or an empty string
+func createdArgument(args map[string]interface{}) (string, error) {
+	value := stringArgument(args, "created")
+	if value == "" {
+		return "", nil
+	}
+
+	recipe := paprika.Recipe{Created: value}
+	created, ok := recipe.CreatedAt()
+	if !ok {
+		return "", fmt.Errorf("invalid created date %q, expected YYYY-MM-DD or YYYY-MM-DD HH:MM:SS", value)
+	}
+	recipe.SetCreated(created)
+	return recipe.Created, nil
+}
@@ -0,0 +1,37 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func (s *Server) cooklangTools() []server.ServerTool {
+	exportRecipeCooklangTool := mcp.NewTool("export_recipe_cooklang",
+		mcp.WithDescription("Render a recipe from the Paprika 3 app in Cooklang (https://cooklang.org), the plain-text recipe format, e.g. to keep it in a .cook file. Ingredients are marked up where the directions mention them."),
+		mcp.WithString("uid", mcp.Description("The UID of the recipe"), mcp.Required()),
+	)
+
+	return []server.ServerTool{
+		{Tool: exportRecipeCooklangTool, Handler: s.exportRecipeCooklang},
+	}
+}
+
+func (s *Server) exportRecipeCooklang(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid := stringArgument(req.Params.Arguments, "uid")
+	if uid == "" {
+		return nil, errors.New("uid is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := s.readRecipe(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(recipe.ToCooklang(s.recipeCategories(ctx, recipe))), nil
+}
@@ -0,0 +1,22 @@
+package mcpserver
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportRecipeCooklang(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "TOAST", Name: "Toast", Ingredients: "2 slices bread", Directions: "Toast the bread."})
+
+	texts := callTool(t, s, "export_recipe_cooklang", map[string]interface{}{"uid": "TOAST"})
+	require.Len(t, texts, 1)
+	assert.Equal(t, "---\ntitle: Toast\nuid: TOAST\n---\n\nToast the @bread{2%slices}.\n", texts[0])
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{"name": "export_recipe_cooklang", "arguments": map[string]interface{}{}})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "uid is required")
+}
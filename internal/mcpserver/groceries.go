@@ -0,0 +1,227 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+func (s *Server) groceryTools() []server.ServerTool {
+	listGroceriesTool := mcp.NewTool("list_groceries",
+		mcp.WithDescription("List the items on the grocery lists in the Paprika 3 app, grouped by aisle"),
+		mcp.WithString("list", mcp.Description("The name of the grocery list to show; defaults to all lists"), mcp.DefaultString("")),
+		mcp.WithBoolean("include_purchased", mcp.Description("Whether to include items that are already checked off"), mcp.DefaultBool(false)),
+	)
+	addToGroceryListTool := mcp.NewTool("add_to_grocery_list",
+		mcp.WithDescription("Add items to a grocery list in the Paprika 3 app"),
+		mcp.WithArray("items", mcp.Description("The items to add, e.g. \"2 onions\""), mcp.Items(map[string]interface{}{"type": "string"}), mcp.Required()),
+		mcp.WithString("list", mcp.Description("The name of the grocery list; defaults to the default list"), mcp.DefaultString("")),
+		mcp.WithString("aisle", mcp.Description("The aisle the items are found in"), mcp.DefaultString("")),
+	)
+	checkGroceryItemTool := mcp.NewTool("check_grocery_item",
+		mcp.WithDescription("Check off a grocery item as purchased in the Paprika 3 app"),
+		mcp.WithString("uid", mcp.Description("The UID of the grocery item"), mcp.Required()),
+		mcp.WithBoolean("purchased", mcp.Description("Set to false to uncheck an item"), mcp.DefaultBool(true)),
+	)
+	removeGroceryItemTool := mcp.NewTool("remove_grocery_item",
+		mcp.WithDescription("Remove an item from its grocery list in the Paprika 3 app"),
+		mcp.WithString("uid", mcp.Description("The UID of the grocery item"), mcp.Required()),
+	)
+
+	return []server.ServerTool{
+		{Tool: listGroceriesTool, Handler: s.listGroceries},
+		{Tool: addToGroceryListTool, Handler: s.addToGroceryList},
+		{Tool: checkGroceryItemTool, Handler: s.checkGroceryItem},
+		{Tool: removeGroceryItemTool, Handler: s.removeGroceryItem},
+	}
+}
+
+func (s *Server) listGroceries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	listName := stringArgument(req.Params.Arguments, "list")
+	includePurchased := boolArgument(req.Params.Arguments, "include_purchased", false)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	lists, err := s.paprika3.ListGroceryLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if listName != "" {
+		list, ok := lists.Find(listName)
+		if !ok {
+			return nil, fmt.Errorf("grocery list %q not found", listName)
+		}
+		lists = paprika.GroceryLists{list}
+	}
+
+	items, err := s.paprika3.ListGroceryItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(groceriesMarkdown(lists, items, includePurchased)), nil
+}
+
+func (s *Server) addToGroceryList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start := time.Now()
+	names, err := stringSliceArgument(req.Params.Arguments, "items")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("items are required")
+	}
+	listName := stringArgument(req.Params.Arguments, "list")
+	aisle := stringArgument(req.Params.Arguments, "aisle")
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	listUID, err := s.groceryListUID(ctx, listName)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]paprika.GroceryItem, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		items = append(items, paprika.GroceryItem{Name: name, Aisle: aisle, ListUID: listUID, OrderFlag: len(items)})
+	}
+
+	saved, err := s.paprika3.SaveGroceryItems(ctx, items...)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start)
+	s.logger.Info("Added grocery items", "count", len(saved), "duration", duration)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Added %d items to the grocery list:\n", len(saved)))
+	for _, item := range saved {
+		sb.WriteString(fmt.Sprintf("- %s (uid: %s)\n", item.Name, item.UID))
+	}
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// groceryListUID returns the UID of the grocery list with the given name, or an empty string
+// for the default list if name is empty
+func (s *Server) groceryListUID(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	lists, err := s.paprika3.ListGroceryLists(ctx)
+	if err != nil {
+		return "", err
+	}
+	list, ok := lists.Find(name)
+	if !ok {
+		return "", fmt.Errorf("grocery list %q not found", name)
+	}
+	return list.UID, nil
+}
+
+func (s *Server) checkGroceryItem(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid, ok := req.Params.Arguments["uid"].(string)
+	if !ok || len(uid) == 0 {
+		return nil, errors.New("uid is required")
+	}
+	purchased := boolArgument(req.Params.Arguments, "purchased", true)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	item, err := s.paprika3.CheckGroceryItem(ctx, uid, purchased)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Checked grocery item", "name", item.Name, "uid", item.UID, "purchased", item.Purchased)
+
+	if item.Purchased {
+		return mcp.NewToolResultText(fmt.Sprintf("Checked off %s", item.Name)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Unchecked %s", item.Name)), nil
+}
+
+func (s *Server) removeGroceryItem(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid, ok := req.Params.Arguments["uid"].(string)
+	if !ok || len(uid) == 0 {
+		return nil, errors.New("uid is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	item, err := s.paprika3.RemoveGroceryItem(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Removed grocery item", "name", item.Name, "uid", item.UID)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Removed %s from the grocery list", item.Name)), nil
+}
+
+// groceriesMarkdown renders the items of the given lists as markdown checklists grouped by aisle
+func groceriesMarkdown(lists paprika.GroceryLists, items []paprika.GroceryItem, includePurchased bool) string {
+	var sb strings.Builder
+
+	for _, list := range lists {
+		byAisle := make(map[string][]paprika.GroceryItem)
+		for _, item := range items {
+			if item.ListUID != list.UID || (item.Purchased && !includePurchased) {
+				continue
+			}
+			byAisle[item.Aisle] = append(byAisle[item.Aisle], item)
+		}
+
+		sb.WriteString(fmt.Sprintf("# %s\n\n", list.Name))
+		if len(byAisle) == 0 {
+			sb.WriteString("_Nothing to buy_\n\n")
+			continue
+		}
+
+		aisles := make([]string, 0, len(byAisle))
+		for aisle := range byAisle {
+			aisles = append(aisles, aisle)
+		}
+		// items without an aisle go last
+		sort.Slice(aisles, func(i, j int) bool {
+			if aisles[i] == "" || aisles[j] == "" {
+				return aisles[j] == ""
+			}
+			return aisles[i] < aisles[j]
+		})
+
+		for _, aisle := range aisles {
+			if aisle == "" {
+				sb.WriteString("## Other\n")
+			} else {
+				sb.WriteString(fmt.Sprintf("## %s\n", aisle))
+			}
+
+			aisleItems := byAisle[aisle]
+			sort.SliceStable(aisleItems, func(i, j int) bool { return aisleItems[i].OrderFlag < aisleItems[j].OrderFlag })
+			for _, item := range aisleItems {
+				check := " "
+				if item.Purchased {
+					check = "x"
+				}
+				sb.WriteString(fmt.Sprintf("- [%s] %s (uid: %s)\n", check, item.Name, item.UID))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+package mcpserver
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroceryTools(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutGroceryList(paprika.GroceryList{UID: "MAIN", Name: "My Grocery List", IsDefault: true})
+	fake.PutGroceryItem(paprika.GroceryItem{UID: "MILK", Name: "Milk", Aisle: "Dairy", ListUID: "MAIN"})
+
+	callTool(t, s, "add_to_grocery_list", map[string]interface{}{
+		"items": []string{"2 onions", " ", "Garlic"},
+		"aisle": "Produce",
+	})
+	items := fake.GroceryItems()
+	require.Len(t, items, 3)
+
+	callTool(t, s, "check_grocery_item", map[string]interface{}{"uid": "MILK"})
+
+	texts := callTool(t, s, "list_groceries", map[string]interface{}{})
+	require.Len(t, texts, 1)
+	assert.Contains(t, texts[0], "# My Grocery List")
+	assert.Contains(t, texts[0], "## Produce\n- [ ] 2 onions")
+	assert.NotContains(t, texts[0], "Milk")
+
+	texts = callTool(t, s, "list_groceries", map[string]interface{}{"include_purchased": true})
+	assert.Contains(t, texts[0], "## Dairy\n- [x] Milk (uid: MILK)")
+
+	callTool(t, s, "remove_grocery_item", map[string]interface{}{"uid": "MILK"})
+	assert.Len(t, fake.GroceryItems(), 2)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "add_to_grocery_list",
+		"arguments": map[string]interface{}{"items": []string{"Eggs"}, "list": "Nope"},
+	})
+	require.NotNil(t, resp.Error)
+}
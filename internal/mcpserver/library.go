@@ -0,0 +1,173 @@
+package mcpserver
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+// library is the server's local copy of the recipe library. It remembers the hash of every
+// recipe it has seen, so a refresh only needs to download recipes that are new or changed.
+type library struct {
+	mu sync.RWMutex
+	// recipes holds the recipes that are exposed as resources, i.e. everything not in the trash
+	recipes map[string]*paprika.Recipe
+	// hashes holds the hash of every known recipe, including those in the trash
+	hashes map[string]string
+	// modified holds when each known recipe was last changed, as far as the server can tell
+	modified   map[string]time.Time
+	categories paprika.Categories
+}
+
+func newLibrary() *library {
+	return &library{
+		recipes:  make(map[string]*paprika.Recipe),
+		hashes:   make(map[string]string),
+		modified: make(map[string]time.Time),
+	}
+}
+
+// changes describes how a refresh changed the library
+type changes struct {
+	added     []string
+	updated   []string
+	removed   []string
+	unchanged int
+}
+
+// record adds the outcome of library.put for the given recipe
+func (c *changes) record(uid string, added, updated, removed bool) {
+	switch {
+	case added:
+		c.added = append(c.added, uid)
+	case updated:
+		c.updated = append(c.updated, uid)
+	case removed:
+		c.removed = append(c.removed, uid)
+	}
+}
+
+func (c changes) empty() bool {
+	return len(c.added) == 0 && len(c.updated) == 0 && len(c.removed) == 0
+}
+
+// diff compares a recipe list against the library, returning the UIDs that
+// need to be downloaded and the UIDs that no longer exist upstream
+func (l *library) diff(list *paprika.RecipeList) (stale []string, gone []string) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	listed := make(map[string]struct{}, len(list.Result))
+	for _, entry := range list.Result {
+		uid := strings.ToUpper(entry.UID)
+		listed[uid] = struct{}{}
+		if hash, ok := l.hashes[uid]; !ok || hash != entry.Hash {
+			stale = append(stale, entry.UID)
+		}
+	}
+
+	for uid := range l.hashes {
+		if _, ok := listed[uid]; !ok {
+			gone = append(gone, uid)
+		}
+	}
+	sort.Strings(gone)
+
+	return stale, gone
+}
+
+// put stores a recipe under the given hash, along with when it was modified. Recipes in the trash are
+// remembered but not exposed. It reports whether the recipe was added or updated as a resource, and whether it was removed.
+func (l *library) put(recipe *paprika.Recipe, hash string, modified time.Time) (added, updated, removed bool) {
+	uid := strings.ToUpper(recipe.UID)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hashes[uid] = hash
+	l.modified[uid] = modified
+	_, exists := l.recipes[uid]
+	if recipe.InTrash {
+		delete(l.recipes, uid)
+		return false, false, exists
+	}
+
+	l.recipes[uid] = recipe
+	return !exists, exists, false
+}
+
+// remove forgets a recipe entirely, reporting whether it was exposed as a resource
+func (l *library) remove(uid string) bool {
+	uid = strings.ToUpper(uid)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, exists := l.recipes[uid]
+	delete(l.recipes, uid)
+	delete(l.hashes, uid)
+	delete(l.modified, uid)
+	return exists
+}
+
+// known reports whether the library has seen a recipe before, including in the trash
+func (l *library) known(uid string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.hashes[strings.ToUpper(uid)]
+	return ok
+}
+
+// modifiedAt returns when a recipe was last changed, as far as the server can tell
+func (l *library) modifiedAt(uid string) (time.Time, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	modified, ok := l.modified[strings.ToUpper(uid)]
+	return modified, ok
+}
+
+// setCategories replaces the categories, reporting whether they changed
+func (l *library) setCategories(categories paprika.Categories) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	changed := !slices.Equal(l.categories, categories)
+	l.categories = categories
+	return changed
+}
+
+// categoryNames returns the names of the given category UIDs
+func (l *library) categoryNames(uids []string) []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.categories.Names(uids)
+}
+
+// get returns the recipe with the given UID along with the current categories
+func (l *library) get(uid string) (*paprika.Recipe, paprika.Categories, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	recipe, ok := l.recipes[strings.ToUpper(uid)]
+	return recipe, l.categories, ok
+}
+
+// all returns every exposed recipe, sorted by name
+func (l *library) all() []*paprika.Recipe {
+	l.mu.RLock()
+	recipes := make([]*paprika.Recipe, 0, len(l.recipes))
+	for _, recipe := range l.recipes {
+		recipes = append(recipes, recipe)
+	}
+	l.mu.RUnlock()
+
+	sort.Slice(recipes, func(i, j int) bool {
+		if recipes[i].Name != recipes[j].Name {
+			return recipes[i].Name < recipes[j].Name
+		}
+		return recipes[i].UID < recipes[j].UID
+	})
+	return recipes
+}
@@ -0,0 +1,159 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+func (s *Server) mealTools() []server.ServerTool {
+	listMealPlanTool := mcp.NewTool("list_meal_plan",
+		mcp.WithDescription("List the meals planned in the Paprika 3 meal planner for a range of dates"),
+		mcp.WithString("start_date", mcp.Description("The first date to list, formatted as YYYY-MM-DD"), mcp.Required()),
+		mcp.WithString("end_date", mcp.Description("The last date to list, formatted as YYYY-MM-DD; defaults to a week after start_date"), mcp.DefaultString("")),
+	)
+	scheduleMealTool := mcp.NewTool("schedule_meal",
+		mcp.WithDescription("Schedule a recipe, or a free-text meal, in the Paprika 3 meal planner"),
+		mcp.WithString("date", mcp.Description("The date of the meal, formatted as YYYY-MM-DD"), mcp.Required()),
+		mcp.WithString("meal_type", mcp.Description("The meal of the day"), mcp.Enum("breakfast", "lunch", "dinner", "snack"), mcp.DefaultString("dinner")),
+		mcp.WithString("recipe_uid", mcp.Description("The UID of the recipe to schedule"), mcp.DefaultString("")),
+		mcp.WithString("name", mcp.Description("The name of the meal; required if no recipe_uid is given"), mcp.DefaultString("")),
+	)
+	unscheduleMealTool := mcp.NewTool("unschedule_meal",
+		mcp.WithDescription("Remove a meal from the Paprika 3 meal planner"),
+		mcp.WithString("uid", mcp.Description("The UID of the meal planner entry"), mcp.Required()),
+	)
+
+	return []server.ServerTool{
+		{Tool: listMealPlanTool, Handler: s.listMealPlan},
+		{Tool: scheduleMealTool, Handler: s.scheduleMeal},
+		{Tool: unscheduleMealTool, Handler: s.unscheduleMeal},
+	}
+}
+
+func (s *Server) listMealPlan(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	startDate, ok := req.Params.Arguments["start_date"].(string)
+	if !ok || len(startDate) == 0 {
+		return nil, errors.New("start_date is required")
+	}
+	start, err := time.Parse(time.DateOnly, startDate)
+	if err != nil {
+		return nil, fmt.Errorf("start_date must be formatted as YYYY-MM-DD: %w", err)
+	}
+	end := start.AddDate(0, 0, 6)
+	if endDate := stringArgument(req.Params.Arguments, "end_date"); endDate != "" {
+		end, err = time.Parse(time.DateOnly, endDate)
+		if err != nil {
+			return nil, fmt.Errorf("end_date must be formatted as YYYY-MM-DD: %w", err)
+		}
+	}
+	if end.Before(start) {
+		return nil, errors.New("end_date must not be before start_date")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	meals, err := s.paprika3.ListMeals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(mealPlanMarkdown(meals.Between(start, end), start, end)), nil
+}
+
+func (s *Server) scheduleMeal(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	date, ok := req.Params.Arguments["date"].(string)
+	if !ok || len(date) == 0 {
+		return nil, errors.New("date is required")
+	}
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return nil, fmt.Errorf("date must be formatted as YYYY-MM-DD: %w", err)
+	}
+	mealType := paprika.Dinner
+	if name := stringArgument(req.Params.Arguments, "meal_type"); name != "" {
+		mealType, err = paprika.ParseMealType(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	recipeUID := stringArgument(req.Params.Arguments, "recipe_uid")
+	name := stringArgument(req.Params.Arguments, "name")
+	if recipeUID == "" && name == "" {
+		return nil, errors.New("either recipe_uid or name is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if recipeUID != "" && name == "" {
+		recipe, err := s.paprika3.GetRecipe(ctx, recipeUID)
+		if err != nil {
+			return nil, err
+		}
+		name = recipe.Name
+	}
+
+	meal, err := s.paprika3.ScheduleMeal(ctx, day, mealType, recipeUID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Scheduled meal", "name", meal.Name, "uid", meal.UID, "date", meal.Date)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Scheduled %s for %s on %s (uid: %s)", meal.Name, meal.Type, day.Format("Monday, January 2"), meal.UID)), nil
+}
+
+func (s *Server) unscheduleMeal(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid, ok := req.Params.Arguments["uid"].(string)
+	if !ok || len(uid) == 0 {
+		return nil, errors.New("uid is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	meal, err := s.paprika3.DeleteMeal(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Unscheduled meal", "name", meal.Name, "uid", meal.UID, "date", meal.Date)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Removed %s from the meal plan", meal.Name)), nil
+}
+
+// mealPlanMarkdown renders meals, which must already be sorted by date, as a markdown agenda
+func mealPlanMarkdown(meals paprika.Meals, start, end time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Meal plan %s to %s\n\n", start.Format(time.DateOnly), end.Format(time.DateOnly)))
+
+	if len(meals) == 0 {
+		sb.WriteString("_No meals planned_\n")
+		return sb.String()
+	}
+
+	var current string
+	for _, meal := range meals {
+		day, _ := meal.Day()
+		if heading := day.Format("Monday, January 2"); heading != current {
+			if current != "" {
+				sb.WriteString("\n")
+			}
+			current = heading
+			sb.WriteString(fmt.Sprintf("## %s\n", heading))
+		}
+
+		sb.WriteString(fmt.Sprintf("- **%s:** %s (uid: %s", meal.Type, meal.Name, meal.UID))
+		if meal.RecipeUID != "" {
+			sb.WriteString(fmt.Sprintf(", recipe: paprika://recipes/%s", meal.RecipeUID))
+		}
+		sb.WriteString(")\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,38 @@
+package mcpserver
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMealTools(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili"})
+
+	callTool(t, s, "schedule_meal", map[string]interface{}{"date": "2025-03-10", "recipe_uid": "CHILI"})
+	callTool(t, s, "schedule_meal", map[string]interface{}{"date": "2025-03-11", "meal_type": "lunch", "name": "Sandwiches"})
+
+	texts := callTool(t, s, "list_meal_plan", map[string]interface{}{"start_date": "2025-03-10"})
+	require.Len(t, texts, 1)
+	assert.Contains(t, texts[0], "# Meal plan 2025-03-10 to 2025-03-16")
+	assert.Contains(t, texts[0], "## Monday, March 10\n- **dinner:** Chili")
+	assert.Contains(t, texts[0], "recipe: paprika://recipes/CHILI")
+	assert.Contains(t, texts[0], "## Tuesday, March 11\n- **lunch:** Sandwiches")
+
+	meals := fake.Meals()
+	require.Len(t, meals, 2)
+	callTool(t, s, "unschedule_meal", map[string]interface{}{"uid": meals[0].UID})
+	assert.Len(t, fake.Meals(), 1)
+
+	texts = callTool(t, s, "list_meal_plan", map[string]interface{}{"start_date": "2025-04-01", "end_date": "2025-04-02"})
+	assert.Contains(t, texts[0], "_No meals planned_")
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "schedule_meal",
+		"arguments": map[string]interface{}{"date": "2025-03-10"},
+	})
+	require.NotNil(t, resp.Error)
+}
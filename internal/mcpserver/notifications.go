@@ -0,0 +1,78 @@
+package mcpserver
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// trackSessions remembers every client that connects, so notifications can be sent
+// outside of a request. mcp-go only does this itself for tool changes.
+func (s *Server) trackSessions(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.sessions.Store(session.SessionID(), session)
+	})
+}
+
+func newNotification(method string, params map[string]any) mcp.JSONRPCNotification {
+	return mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: method,
+			Params: mcp.NotificationParams{
+				AdditionalFields: params,
+			},
+		},
+	}
+}
+
+// notifySession sends a notification to an initialized client. Clients that aren't
+// keeping up with their notifications miss it rather than block the server.
+func (s *Server) notifySession(session server.ClientSession, notification mcp.JSONRPCNotification) {
+	if !session.Initialized() {
+		return
+	}
+
+	select {
+	case session.NotificationChannel() <- notification:
+	default:
+		s.logger.Warn("dropped notification for blocked client", "method", notification.Method, "session", session.SessionID())
+	}
+}
+
+// notifyClients sends a notification to every client
+func (s *Server) notifyClients(method string, params map[string]any) {
+	notification := newNotification(method, params)
+	s.sessions.Range(func(key, value any) bool {
+		s.notifySession(value.(server.ClientSession), notification)
+		return true
+	})
+}
+
+// notifySubscribers tells the clients subscribed to a resource that it changed
+func (s *Server) notifySubscribers(uri string) {
+	notification := newNotification("notifications/resources/updated", map[string]any{"uri": uri})
+	for _, id := range s.subscriptions.subscribers(uri) {
+		if session, ok := s.sessions.Load(id); ok {
+			s.notifySession(session.(server.ClientSession), notification)
+		}
+	}
+}
+
+// notifyResourceChanges tells clients that the resource list changed and tells subscribers
+// which recipes were edited or went away. Updated recipes change the list too,
+// since their name and description are part of it.
+func (s *Server) notifyResourceChanges(c changes) {
+	if c.empty() {
+		return
+	}
+
+	s.notifyClients("notifications/resources/list_changed", nil)
+	for _, uid := range c.updated {
+		s.notifySubscribers(recipeURI(uid))
+	}
+	for _, uid := range c.removed {
+		s.notifySubscribers(recipeURI(uid))
+	}
+}
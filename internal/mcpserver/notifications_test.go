@@ -0,0 +1,92 @@
+package mcpserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (t *testSession) Initialize()       {}
+func (t *testSession) Initialized() bool { return true }
+func (t *testSession) SessionID() string { return "test" }
+func (t *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return t.notifications
+}
+
+// connect registers a client session with the server and returns it
+func connect(t *testing.T, s *Server) *testSession {
+	t.Helper()
+
+	session := &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, s.server.RegisterSession(context.Background(), session))
+	return session
+}
+
+// drain returns the methods of all pending notifications
+func (t *testSession) drain() []string {
+	var methods []string
+	for {
+		select {
+		case n := <-t.notifications:
+			methods = append(methods, n.Method)
+		default:
+			return methods
+		}
+	}
+}
+
+func TestResourceListChangedNotifications(t *testing.T) {
+	s, fake := newTestServer(t)
+	session := connect(t, s)
+
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup"})
+	s.refreshResources()
+	assert.Equal(t, []string{"notifications/resources/list_changed"}, session.drain())
+
+	// nothing changed, so nothing is sent
+	s.refreshResources()
+	assert.Empty(t, session.drain())
+
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", InTrash: true})
+	s.refreshResources()
+	assert.Equal(t, []string{"notifications/resources/list_changed"}, session.drain())
+	assert.Empty(t, listRecipeResources(t, s))
+}
+
+func TestCreateRecipeNotifiesClients(t *testing.T) {
+	s, _ := newTestServer(t)
+	session := connect(t, s)
+
+	callTool(t, s, "create_paprika_recipe", map[string]interface{}{
+		"name":        "Toast",
+		"ingredients": "bread",
+		"directions":  "toast",
+		"description": "",
+		"notes":       "",
+		"servings":    "",
+		"prep_time":   "",
+		"cook_time":   "",
+		"difficulty":  "",
+	})
+	assert.Equal(t, []string{"notifications/resources/list_changed"}, session.drain())
+}
+
+func TestResourceCapabilities(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	resp := rpc(t, s, "initialize", map[string]interface{}{
+		"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+		"clientInfo":      map[string]interface{}{"name": "test", "version": "test"},
+		"capabilities":    map[string]interface{}{},
+	})
+	require.Nil(t, resp.Error)
+	assert.Contains(t, string(resp.Result), `"listChanged":true`)
+}
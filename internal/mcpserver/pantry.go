@@ -0,0 +1,231 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+const (
+	expiringPantryURI = "paprika://pantry/expiring"
+	// expiringWithin is how far ahead the expiring pantry resource looks
+	expiringWithin = 7 * 24 * time.Hour
+)
+
+func (s *Server) pantryTools() []server.ServerTool {
+	listPantryTool := mcp.NewTool("list_pantry",
+		mcp.WithDescription("List the ingredients in the Paprika 3 pantry, e.g. to find out what can be cooked with what's at home"),
+		mcp.WithBoolean("include_out_of_stock", mcp.Description("Whether to include items that are out of stock"), mcp.DefaultBool(false)),
+	)
+	savePantryItemTool := mcp.NewTool("save_pantry_item",
+		mcp.WithDescription("Add an ingredient to the Paprika 3 pantry, or update an existing pantry item"),
+		mcp.WithString("uid", mcp.Description("The UID of the pantry item to update; omit to add a new item"), mcp.DefaultString("")),
+		mcp.WithString("ingredient", mcp.Description("The ingredient, e.g. \"eggs\"; required for new items"), mcp.DefaultString("")),
+		mcp.WithString("quantity", mcp.Description("How much of the ingredient there is, e.g. \"12\""), mcp.DefaultString("")),
+		mcp.WithString("aisle", mcp.Description("The aisle the ingredient is found in"), mcp.DefaultString("")),
+		mcp.WithString("purchase_date", mcp.Description("When the ingredient was bought, formatted as YYYY-MM-DD"), mcp.DefaultString("")),
+		mcp.WithString("expiration_date", mcp.Description("When the ingredient expires, formatted as YYYY-MM-DD"), mcp.DefaultString("")),
+		mcp.WithBoolean("in_stock", mcp.Description("Whether the ingredient is in stock"), mcp.DefaultBool(true)),
+	)
+	removePantryItemTool := mcp.NewTool("remove_pantry_item",
+		mcp.WithDescription("Remove an ingredient from the Paprika 3 pantry"),
+		mcp.WithString("uid", mcp.Description("The UID of the pantry item"), mcp.Required()),
+	)
+
+	return []server.ServerTool{
+		{Tool: listPantryTool, Handler: s.listPantry},
+		{Tool: savePantryItemTool, Handler: s.savePantryItem},
+		{Tool: removePantryItemTool, Handler: s.removePantryItem},
+	}
+}
+
+// addPantryResources exposes pantry items that are about to expire as a resource
+func (s *Server) addPantryResources() {
+	s.server.AddResource(mcp.NewResource(expiringPantryURI, "Expiring pantry items",
+		mcp.WithResourceDescription("Ingredients in the Paprika 3 pantry that expire within the next week or have already expired"),
+		mcp.WithMIMEType("text/markdown"),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		items, err := s.paprika3.ListPantryItems(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      expiringPantryURI,
+			MIMEType: "text/markdown",
+			Text:     expiringPantryMarkdown(items.ExpiringBefore(now.Add(expiringWithin)), now),
+		}}, nil
+	})
+}
+
+func (s *Server) listPantry(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	includeOutOfStock := boolArgument(req.Params.Arguments, "include_out_of_stock", false)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	items, err := s.paprika3.ListPantryItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !includeOutOfStock {
+		items = items.InStock()
+	}
+
+	return mcp.NewToolResultText(pantryMarkdown(items)), nil
+}
+
+func (s *Server) savePantryItem(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.Params.Arguments
+	uid := stringArgument(args, "uid")
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	item := paprika.PantryItem{InStock: true}
+	if uid != "" {
+		items, err := s.paprika3.ListPantryItems(ctx)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, existing := range items {
+			if strings.EqualFold(existing.UID, uid) {
+				item, found = existing, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("pantry item %s not found", uid)
+		}
+	}
+
+	if ingredient := stringArgument(args, "ingredient"); ingredient != "" {
+		item.Ingredient = ingredient
+	}
+	if item.Ingredient == "" {
+		return nil, errors.New("ingredient is required")
+	}
+	if quantity := stringArgument(args, "quantity"); quantity != "" {
+		item.Quantity = quantity
+	}
+	if aisle := stringArgument(args, "aisle"); aisle != "" {
+		item.Aisle = aisle
+	}
+	dates := []struct {
+		name  string
+		field *string
+	}{
+		{"purchase_date", &item.PurchaseDate},
+		{"expiration_date", &item.ExpirationDate},
+	}
+	for _, d := range dates {
+		value := stringArgument(args, d.name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be formatted as YYYY-MM-DD: %w", d.name, err)
+		}
+		*d.field = date.Format(paprika.DateLayout)
+	}
+	item.InStock = boolArgument(args, "in_stock", item.InStock)
+
+	saved, err := s.paprika3.SavePantryItems(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Saved pantry item", "ingredient", saved[0].Ingredient, "uid", saved[0].UID)
+
+	return mcp.NewToolResultText(pantryMarkdown(saved)), nil
+}
+
+func (s *Server) removePantryItem(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid, ok := req.Params.Arguments["uid"].(string)
+	if !ok || len(uid) == 0 {
+		return nil, errors.New("uid is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	item, err := s.paprika3.DeletePantryItem(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Removed pantry item", "ingredient", item.Ingredient, "uid", item.UID)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Removed %s from the pantry", item.Ingredient)), nil
+}
+
+// pantryMarkdown renders pantry items as a markdown list
+func pantryMarkdown(items paprika.PantryItems) string {
+	if len(items) == 0 {
+		return "_The pantry is empty_\n"
+	}
+
+	var sb strings.Builder
+	for _, item := range items {
+		sb.WriteString("- ")
+		if item.Quantity != "" {
+			sb.WriteString(item.Quantity + " ")
+		}
+		sb.WriteString(item.Ingredient)
+
+		details := []string{"uid: " + item.UID}
+		if item.Aisle != "" {
+			details = append(details, "aisle: "+item.Aisle)
+		}
+		if expiration, ok := item.Expiration(); ok {
+			details = append(details, "expires: "+expiration.Format(time.DateOnly))
+		}
+		if !item.InStock {
+			details = append(details, "out of stock")
+		}
+		sb.WriteString(fmt.Sprintf(" (%s)\n", strings.Join(details, ", ")))
+	}
+
+	return sb.String()
+}
+
+// expiringPantryMarkdown renders items, which must be sorted by expiration, relative to now
+func expiringPantryMarkdown(items paprika.PantryItems, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("# Expiring pantry items\n\n")
+
+	if len(items) == 0 {
+		sb.WriteString("_Nothing expires within the next week_\n")
+		return sb.String()
+	}
+
+	// items expire at the end of their day, so compare calendar dates in the local time zone
+	year, month, day := now.In(time.Local).Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	for _, item := range items {
+		expiration, _ := item.Expiration()
+		status := fmt.Sprintf("expires %s", expiration.Format(time.DateOnly))
+		year, month, day := expiration.Date()
+		if time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Before(today) {
+			status = fmt.Sprintf("expired %s", expiration.Format(time.DateOnly))
+		}
+
+		name := item.Ingredient
+		if item.Quantity != "" {
+			name = item.Quantity + " " + name
+		}
+		sb.WriteString(fmt.Sprintf("- **%s** %s (uid: %s)\n", name, status, item.UID))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package mcpserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPantryTools(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutPantryItem(paprika.PantryItem{UID: "RICE", Ingredient: "rice", InStock: false})
+
+	callTool(t, s, "save_pantry_item", map[string]interface{}{
+		"ingredient":      "eggs",
+		"quantity":        "12",
+		"expiration_date": "2025-03-05",
+	})
+	items := fake.PantryItems()
+	require.Len(t, items, 2)
+
+	var eggs paprika.PantryItem
+	for _, item := range items {
+		if item.Ingredient == "eggs" {
+			eggs = item
+		}
+	}
+	assert.Equal(t, "2025-03-05 00:00:00", eggs.ExpirationDate)
+	assert.True(t, eggs.InStock)
+
+	callTool(t, s, "save_pantry_item", map[string]interface{}{"uid": eggs.UID, "quantity": "6"})
+
+	texts := callTool(t, s, "list_pantry", map[string]interface{}{})
+	require.Len(t, texts, 1)
+	assert.Contains(t, texts[0], "- 6 eggs (uid: "+eggs.UID+", expires: 2025-03-05)")
+	assert.NotContains(t, texts[0], "rice")
+
+	texts = callTool(t, s, "list_pantry", map[string]interface{}{"include_out_of_stock": true})
+	assert.Contains(t, texts[0], "- rice (uid: RICE, out of stock)")
+
+	callTool(t, s, "remove_pantry_item", map[string]interface{}{"uid": "RICE"})
+	assert.Len(t, fake.PantryItems(), 1)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "save_pantry_item",
+		"arguments": map[string]interface{}{"quantity": "1"},
+	})
+	require.NotNil(t, resp.Error)
+}
+
+func TestExpiringPantryResource(t *testing.T) {
+	s, fake := newTestServer(t)
+	soon := time.Now().AddDate(0, 0, 2).Format(paprika.DateLayout)
+	later := time.Now().AddDate(0, 1, 0).Format(paprika.DateLayout)
+	fake.PutPantryItem(paprika.PantryItem{UID: "MILK", Ingredient: "milk", InStock: true, HasExpiration: true, ExpirationDate: soon})
+	fake.PutPantryItem(paprika.PantryItem{UID: "JAM", Ingredient: "jam", InStock: true, HasExpiration: true, ExpirationDate: later})
+
+	resp := rpc(t, s, "resources/read", map[string]interface{}{"uri": expiringPantryURI})
+	require.Nil(t, resp.Error)
+	assert.Contains(t, string(resp.Result), "milk")
+	assert.NotContains(t, string(resp.Result), "jam")
+}
+
+func TestExpiringPantryMarkdown(t *testing.T) {
+	now := time.Date(2025, 3, 5, 18, 0, 0, 0, time.Local)
+	items := paprika.PantryItems{
+		{UID: "BREAD", Ingredient: "bread", HasExpiration: true, ExpirationDate: "2025-03-04 00:00:00"},
+		{UID: "MILK", Ingredient: "milk", HasExpiration: true, ExpirationDate: "2025-03-05 00:00:00"},
+		{UID: "EGGS", Ingredient: "eggs", HasExpiration: true, ExpirationDate: "2025-03-06 00:00:00"},
+	}
+
+	text := expiringPantryMarkdown(items, now)
+	assert.Contains(t, text, "- **bread** expired 2025-03-04 (uid: BREAD)")
+	// items expiring today haven't expired yet
+	assert.Contains(t, text, "- **milk** expires 2025-03-05 (uid: MILK)")
+	assert.Contains(t, text, "- **eggs** expires 2025-03-06 (uid: EGGS)")
+}
@@ -0,0 +1,113 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+func (s *Server) recipeTools() []server.ServerTool {
+	getRecipeTool := mcp.NewTool("get_paprika_recipe",
+		mcp.WithDescription("Get a recipe from the Paprika 3 app, as markdown and as structured JSON with every field"),
+		mcp.WithString("uid", mcp.Description("The UID of the recipe"), mcp.Required()),
+	)
+	deleteRecipeTool := mcp.NewTool("delete_paprika_recipe",
+		mcp.WithDescription("Move a recipe in the Paprika 3 app to the trash. It can be restored in the app until the trash is emptied."),
+		mcp.WithString("uid", mcp.Description("The UID of the recipe"), mcp.Required()),
+		mcp.WithBoolean("confirm", mcp.Description("Must be true to delete the recipe; ask the user before setting it"), mcp.Required()),
+	)
+
+	return []server.ServerTool{
+		{Tool: getRecipeTool, Handler: s.getRecipe},
+		{Tool: deleteRecipeTool, Handler: s.deleteRecipe},
+	}
+}
+
+// recipeJSON is the structured form of a recipe returned by get_paprika_recipe
+type recipeJSON struct {
+	*paprika.Recipe
+	// CategoryNames are the names of the categories, which the recipe only references by UID
+	CategoryNames []string `json:"category_names"`
+}
+
+func (s *Server) getRecipe(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid, ok := req.Params.Arguments["uid"].(string)
+	if !ok || len(uid) == 0 {
+		return nil, errors.New("uid is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := s.readRecipe(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := s.recipeCategories(ctx, recipe)
+	data, err := json.Marshal(recipeJSON{Recipe: recipe, CategoryNames: categories.Names(recipe.Categories)})
+	if err != nil {
+		return nil, err
+	}
+
+	result := recipeResultWithCategories(recipe, categories)
+	result.Content = append(result.Content, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+		URI:      recipeURI(recipe.UID),
+		MIMEType: "application/json",
+		Text:     string(data),
+	}))
+	return result, nil
+}
+
+// readRecipe gets a recipe from Paprika, falling back to the library's copy if Paprika can't be reached
+func (s *Server) readRecipe(ctx context.Context, uid string) (*paprika.Recipe, error) {
+	recipe, err := s.paprika3.GetRecipe(ctx, uid)
+	if err != nil {
+		cached, _, ok := s.library.get(uid)
+		if !ok {
+			return nil, err
+		}
+		s.logger.Warn("failed to get recipe, using the cached copy", "uid", uid, "err", err)
+		return cached, nil
+	}
+	return recipe, nil
+}
+
+func (s *Server) deleteRecipe(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid, ok := req.Params.Arguments["uid"].(string)
+	if !ok || len(uid) == 0 {
+		return nil, errors.New("uid is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := s.paprika3.GetRecipe(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if recipe.InTrash {
+		return nil, fmt.Errorf("%s is already in the trash", recipe.Name)
+	}
+	if !boolArgument(req.Params.Arguments, "confirm", false) {
+		return nil, fmt.Errorf("confirm must be true to move %s to the trash", recipe.Name)
+	}
+
+	trashed, err := s.paprika3.DeleteRecipe(ctx, *recipe, paprika.WithExpectedHash(recipe.Hash))
+	if err != nil {
+		return nil, conflictError(err)
+	}
+
+	var c changes
+	added, updated, removed := s.storeRecipe(trashed, trashed.Hash, time.Now())
+	c.record(trashed.UID, added, updated, removed)
+	s.notifyResourceChanges(c)
+
+	s.logger.Info("Deleted recipe", "name", trashed.Name, "uid", trashed.UID)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Moved %s to the trash", trashed.Name)), nil
+}
@@ -0,0 +1,83 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRecipe(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili", Ingredients: "beans", Categories: []string{"DINNER"}, Rating: 4, SourceURL: "https://example.com/chili"})
+
+	categoryLists := fake.Requests("GET", "/api/v2/sync/categories")
+	texts := callTool(t, s, "get_paprika_recipe", map[string]interface{}{"uid": "CHILI"})
+	require.Len(t, texts, 3)
+	assert.Equal(t, categoryLists+1, fake.Requests("GET", "/api/v2/sync/categories"), "the categories are listed once")
+	assert.Equal(t, "Chili", texts[0])
+	assert.Contains(t, texts[1], "# Chili")
+	assert.Contains(t, texts[1], "- **Categories:** Dinner")
+
+	var recipe struct {
+		paprika.Recipe
+		CategoryNames []string `json:"category_names"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(texts[2]), &recipe))
+	assert.Equal(t, "CHILI", recipe.UID)
+	assert.Equal(t, 4, recipe.Rating)
+	assert.Equal(t, "https://example.com/chili", recipe.SourceURL)
+	assert.Equal(t, []string{"DINNER"}, recipe.Categories)
+	assert.Equal(t, []string{"Dinner"}, recipe.CategoryNames)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "get_paprika_recipe",
+		"arguments": map[string]interface{}{"uid": "MISSING"},
+	})
+	require.NotNil(t, resp.Error)
+}
+
+func TestGetRecipeFallsBackToLibrary(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili"})
+	s.refreshResources()
+	fake.Close()
+
+	texts := callTool(t, s, "get_paprika_recipe", map[string]interface{}{"uid": "chili"})
+	require.Len(t, texts, 3)
+	assert.Contains(t, texts[1], "# Chili")
+}
+
+func TestDeleteRecipe(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili"})
+	s.refreshResources()
+	session := connect(t, s)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "delete_paprika_recipe",
+		"arguments": map[string]interface{}{"uid": "CHILI", "confirm": false},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "confirm must be true to move Chili to the trash")
+	recipe, _ := fake.Recipe("CHILI")
+	assert.False(t, recipe.InTrash)
+
+	texts := callTool(t, s, "delete_paprika_recipe", map[string]interface{}{"uid": "CHILI", "confirm": true})
+	assert.Equal(t, []string{"Moved Chili to the trash"}, texts)
+	recipe, _ = fake.Recipe("CHILI")
+	assert.True(t, recipe.InTrash)
+
+	assert.Empty(t, listRecipeResources(t, s))
+	assert.Equal(t, []string{"notifications/resources/list_changed"}, session.drain())
+
+	resp = rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "delete_paprika_recipe",
+		"arguments": map[string]interface{}{"uid": "CHILI", "confirm": true},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "already in the trash")
+}
@@ -0,0 +1,100 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+func (s *Server) scaleTools() []server.ServerTool {
+	scaleRecipeTool := mcp.NewTool("scale_recipe",
+		mcp.WithDescription("Scale the ingredients of a recipe in the Paprika 3 app by a factor or to a number of servings, e.g. to double or halve it. Returns the scaled recipe as markdown and can save it as a new recipe; the original is never changed."),
+		mcp.WithString("uid", mcp.Description("The UID of the recipe"), mcp.Required()),
+		mcp.WithNumber("factor", mcp.Description("How much to multiply the ingredients by, e.g. 2 to double or 0.5 to halve the recipe. Pass either factor or servings.")),
+		mcp.WithNumber("servings", mcp.Description("The number of servings to scale the recipe to. Pass either factor or servings.")),
+		mcp.WithBoolean("save", mcp.Description("Save the scaled recipe as a new recipe"), mcp.DefaultBool(false)),
+		mcp.WithString("name", mcp.Description("The name of the saved copy; defaults to the original name with the scale, e.g. \"Pancakes (x2)\""), mcp.DefaultString("")),
+	)
+
+	return []server.ServerTool{
+		{Tool: scaleRecipeTool, Handler: s.scaleRecipe},
+	}
+}
+
+func (s *Server) scaleRecipe(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.Params.Arguments
+	uid := stringArgument(args, "uid")
+	if uid == "" {
+		return nil, errors.New("uid is required")
+	}
+	factor := floatArgument(args, "factor", 0)
+	servings := floatArgument(args, "servings", 0)
+	if (factor == 0) == (servings == 0) {
+		return nil, errors.New("pass either factor or servings")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := s.readRecipe(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var scaled *paprika.Recipe
+	var label string
+	if servings != 0 {
+		scaled, err = recipe.ScaledToServings(servings)
+		label = fmt.Sprintf("%s servings", paprika.Quantity{Value: servings})
+	} else {
+		scaled, err = recipe.Scaled(factor)
+		label = fmt.Sprintf("x%s", paprika.Quantity{Value: factor})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !boolArgument(args, "save", false) {
+		categories := s.recipeCategories(ctx, recipe)
+		return mcp.NewToolResultText(scaled.ToMarkdown(paprika.WithCategories(categories))), nil
+	}
+
+	name := strings.TrimSpace(stringArgument(args, "name"))
+	if name == "" {
+		name = fmt.Sprintf("%s (%s)", recipe.Name, label)
+	}
+	saved, err := s.paprika3.SaveRecipe(ctx, copyRecipe(scaled, name))
+	if err != nil {
+		return nil, err
+	}
+
+	var c changes
+	added, updated, removed := s.storeRecipe(saved, saved.Hash, time.Now())
+	c.record(saved.UID, added, updated, removed)
+	s.notifyResourceChanges(c)
+
+	s.logger.Info("Saved scaled recipe", "name", saved.Name, "uid", saved.UID, "original", recipe.UID)
+
+	return s.recipeResult(ctx, saved), nil
+}
+
+// copyRecipe returns a recipe that can be saved as a new copy of r. The photos are left out,
+// since they belong to the original.
+func copyRecipe(r *paprika.Recipe, name string) paprika.Recipe {
+	c := *r
+	c.UID = ""
+	c.Hash = ""
+	c.Created = ""
+	c.Name = name
+	c.InTrash = false
+	c.Photo = ""
+	c.PhotoHash = ""
+	c.PhotoLarge = ""
+	c.PhotoURL = ""
+	return c
+}
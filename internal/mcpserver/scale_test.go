@@ -0,0 +1,48 @@
+package mcpserver
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleRecipe(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "PANCAKES", Name: "Pancakes", Servings: "4", Ingredients: "1 cup flour\n4 eggs", Photo: "pancakes.jpg"})
+	s.refreshResources()
+
+	texts := callTool(t, s, "scale_recipe", map[string]interface{}{"uid": "PANCAKES", "factor": 1.5})
+	require.Len(t, texts, 1)
+	assert.Contains(t, texts[0], "- 1 1/2 cups flour\n- 6 eggs\n")
+	assert.Contains(t, texts[0], "- **Servings:** 6\n")
+	assert.Len(t, fake.Recipes(), 1)
+
+	texts = callTool(t, s, "scale_recipe", map[string]interface{}{"uid": "PANCAKES", "servings": 2, "save": true})
+	require.Len(t, texts, 2)
+	assert.Equal(t, "Pancakes (2 servings)", texts[0])
+
+	recipes := fake.Recipes()
+	require.Len(t, recipes, 2)
+	var copied paprika.Recipe
+	for _, r := range recipes {
+		if r.UID != "PANCAKES" {
+			copied = r
+		}
+	}
+	assert.Equal(t, "1/2 cup flour\n2 eggs", copied.Ingredients)
+	assert.Empty(t, copied.Photo)
+	assert.Contains(t, listRecipeResources(t, s), recipeURI(copied.UID))
+
+	original, ok := fake.Recipe("PANCAKES")
+	require.True(t, ok)
+	assert.Equal(t, "1 cup flour\n4 eggs", original.Ingredients)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "scale_recipe",
+		"arguments": map[string]interface{}{"uid": "PANCAKES", "factor": 2, "servings": 8},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "either factor or servings")
+}
@@ -0,0 +1,105 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+func (s *Server) schemaOrgTools() []server.ServerTool {
+	importRecipeFromHTMLTool := mcp.NewTool("import_recipe_from_html",
+		mcp.WithDescription("Import a recipe into the Paprika 3 app from a recipe web page, like the Paprika app's browser does. The recipe is read from the schema.org JSON-LD embedded in the page. Provide either the HTML or the path of a locally saved page."),
+		mcp.WithString("html", mcp.Description("The HTML of the page"), mcp.DefaultString("")),
+		mcp.WithString("path", mcp.Description("The absolute path of a saved HTML page"), mcp.DefaultString("")),
+		mcp.WithString("url", mcp.Description("The address of the page, saved as the recipe's source"), mcp.DefaultString("")),
+		mcp.WithArray("categories", mcp.Description("Names of categories to add the recipe to, in addition to the page's own categories and cuisines"), mcp.Items(map[string]interface{}{"type": "string"})),
+	)
+
+	exportRecipeJSONLDTool := mcp.NewTool("export_recipe_json_ld",
+		mcp.WithDescription("Render a recipe from the Paprika 3 app as schema.org Recipe JSON-LD, for publishing it on a web page"),
+		mcp.WithString("uid", mcp.Description("The UID of the recipe"), mcp.Required()),
+	)
+
+	return []server.ServerTool{
+		{Tool: importRecipeFromHTMLTool, Handler: s.importRecipeFromHTML},
+		{Tool: exportRecipeJSONLDTool, Handler: s.exportRecipeJSONLD},
+	}
+}
+
+func (s *Server) importRecipeFromHTML(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start := time.Now()
+	args := req.Params.Arguments
+	page := stringArgument(args, "html")
+	if path := stringArgument(args, "path"); path != "" {
+		if page != "" {
+			return nil, errors.New("provide either html or path, not both")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		page = string(data)
+	}
+	if page == "" {
+		return nil, errors.New("html or path is required")
+	}
+	extra, err := stringSliceArgument(args, "categories")
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := paprika.RecipeFromHTML(page, stringArgument(args, "url"))
+	if err != nil {
+		return nil, err
+	}
+	if recipe.Name == "" {
+		return nil, errors.New("the recipe on the page has no name")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if recipe.Categories, err = s.resolveCategories(ctx, append(recipe.Categories, extra...)); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.paprika3.SaveRecipe(ctx, *recipe)
+	if err != nil {
+		return nil, err
+	}
+
+	var c changes
+	added, updated, removed := s.storeRecipe(saved, saved.Hash, time.Now())
+	c.record(saved.UID, added, updated, removed)
+	s.notifyResourceChanges(c)
+
+	duration := time.Since(start)
+	s.logger.Info("Imported recipe from HTML", "name", saved.Name, "uid", saved.UID, "duration", duration)
+
+	return s.recipeResult(ctx, saved), nil
+}
+
+func (s *Server) exportRecipeJSONLD(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid := stringArgument(req.Params.Arguments, "uid")
+	if uid == "" {
+		return nil, errors.New("uid is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := s.readRecipe(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := recipe.MarshalJSONLD(s.recipeCategories(ctx, recipe))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", recipe.Name, err)
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
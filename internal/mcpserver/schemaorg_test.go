@@ -0,0 +1,61 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportRecipeFromHTML(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+
+	path := filepath.Join(t.TempDir(), "soup.html")
+	require.NoError(t, os.WriteFile(path, []byte(`<script type="application/ld+json">{"@type": "Recipe", "name": "Soup",
+		"recipeCategory": "dinner", "recipeIngredient": ["1 onion"], "recipeInstructions": [{"@type": "HowToStep", "text": "Cook."}]}</script>`), 0o644))
+
+	texts := callTool(t, s, "import_recipe_from_html", map[string]interface{}{
+		"path":       path,
+		"url":        "https://example.com/soup",
+		"categories": []string{"Quick"},
+	})
+	require.Len(t, texts, 2)
+	assert.Contains(t, texts[1], "# Soup")
+	assert.Contains(t, texts[1], "- **Categories:** Dinner, Quick\n")
+
+	recipes := fake.Recipes()
+	require.Len(t, recipes, 1)
+	assert.Equal(t, "1 onion", recipes[0].Ingredients)
+	assert.Equal(t, "Cook.", recipes[0].Directions)
+	assert.Equal(t, "example.com", recipes[0].Source)
+	assert.Equal(t, "https://example.com/soup", recipes[0].SourceURL)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "import_recipe_from_html",
+		"arguments": map[string]interface{}{"html": "<html></html>"},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "no schema.org recipe found")
+}
+
+func TestExportRecipeJSONLD(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	fake.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup", Ingredients: "1 onion", Directions: "Cook.", CookTime: "20 mins", Categories: []string{"DINNER"}})
+
+	texts := callTool(t, s, "export_recipe_json_ld", map[string]interface{}{"uid": "SOUP"})
+	require.Len(t, texts, 1)
+
+	var recipe paprika.SchemaRecipe
+	require.NoError(t, json.Unmarshal([]byte(texts[0]), &recipe))
+	assert.Equal(t, "https://schema.org", recipe.Context)
+	assert.Equal(t, "Soup", recipe.Name)
+	assert.Equal(t, "PT20M", recipe.CookTime)
+	assert.Equal(t, []string{"Dinner"}, recipe.RecipeCategory)
+	assert.Equal(t, []string{"1 onion"}, recipe.RecipeIngredient)
+}
@@ -0,0 +1,85 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/search"
+)
+
+func (s *Server) searchTools() []server.ServerTool {
+	searchRecipesTool := mcp.NewTool("search_recipes",
+		mcp.WithDescription("Search the Paprika 3 recipe library by keywords and filters. Returns matching recipes, best match first, with their UIDs and a snippet showing why they matched."),
+		mcp.WithString("query", mcp.Description("Keywords to look for in the name, ingredients, directions, notes, categories and source; every keyword must match. Omit to only filter."), mcp.DefaultString("")),
+		mcp.WithNumber("min_rating", mcp.Description("Only return recipes rated at least this many stars (0-5)"), mcp.DefaultNumber(0)),
+		mcp.WithBoolean("favorites", mcp.Description("Only return favourite recipes"), mcp.DefaultBool(false)),
+		mcp.WithBoolean("pinned", mcp.Description("Only return pinned recipes"), mcp.DefaultBool(false)),
+		mcp.WithNumber("max_total_minutes", mcp.Description("Only return recipes that take at most this many minutes in total"), mcp.DefaultNumber(0)),
+		mcp.WithString("difficulty", mcp.Description("Only return recipes with this difficulty, e.g. \"Easy\""), mcp.DefaultString("")),
+		mcp.WithNumber("limit", mcp.Description("The maximum number of recipes to return"), mcp.DefaultNumber(10)),
+	)
+
+	return []server.ServerTool{
+		{Tool: searchRecipesTool, Handler: s.searchRecipes},
+	}
+}
+
+// reindex rebuilds the search index from the library, e.g. after categories were renamed
+func (s *Server) reindex() {
+	for _, recipe := range s.library.all() {
+		s.index.Add(recipe, s.library.categoryNames(recipe.Categories))
+	}
+}
+
+func (s *Server) searchRecipes(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.Params.Arguments
+	query := search.Query{
+		Text:         strings.TrimSpace(stringArgument(args, "query")),
+		MinRating:    intArgument(args, "min_rating", 0),
+		Favorites:    boolArgument(args, "favorites", false),
+		Pinned:       boolArgument(args, "pinned", false),
+		MaxTotalTime: time.Duration(intArgument(args, "max_total_minutes", 0)) * time.Minute,
+		Difficulty:   stringArgument(args, "difficulty"),
+		Limit:        intArgument(args, "limit", 10),
+	}
+	if query.Limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := s.awaitLibrary(ctx); err != nil {
+		return nil, err
+	}
+
+	results := s.index.Search(query)
+	s.logger.Info("Searched recipes", "query", query.Text, "results", len(results))
+
+	return mcp.NewToolResultText(searchResultsMarkdown(results)), nil
+}
+
+// searchResultsMarkdown renders search results as a ranked markdown list
+func searchResultsMarkdown(results []search.Result) string {
+	if len(results) == 0 {
+		return "_No recipes found_\n"
+	}
+
+	var sb strings.Builder
+	for i, result := range results {
+		sb.WriteString(fmt.Sprintf("%d. **%s** (uid: %s", i+1, result.Recipe.Name, result.Recipe.UID))
+		if result.Recipe.Rating > 0 {
+			sb.WriteString(fmt.Sprintf(", rating: %d/5", result.Recipe.Rating))
+		}
+		sb.WriteString(")\n")
+		if result.Snippet != "" {
+			sb.WriteString(fmt.Sprintf("   > %s\n", result.Snippet))
+		}
+	}
+
+	return sb.String()
+}
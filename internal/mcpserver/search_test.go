@@ -0,0 +1,45 @@
+package mcpserver
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchRecipes(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili", Ingredients: "2 cans beans\n1 onion", Categories: []string{"DINNER"}, Rating: 5})
+	fake.PutRecipe(paprika.Recipe{UID: "SALAD", Name: "Bean Salad", Ingredients: "1 can beans"})
+	fake.PutRecipe(paprika.Recipe{UID: "OLD", Name: "Old Beans", InTrash: true})
+	s.refreshResources()
+
+	texts := callTool(t, s, "search_recipes", map[string]interface{}{"query": "beans"})
+	require.Len(t, texts, 1)
+	assert.Equal(t, "1. **Bean Salad** (uid: SALAD)\n   > 1 can beans\n2. **Chili** (uid: CHILI, rating: 5/5)\n   > 2 cans beans\n", texts[0])
+
+	texts = callTool(t, s, "search_recipes", map[string]interface{}{"query": "dinner"})
+	assert.Contains(t, texts[0], "uid: CHILI")
+	assert.NotContains(t, texts[0], "uid: SALAD")
+
+	texts = callTool(t, s, "search_recipes", map[string]interface{}{"query": "beans", "min_rating": 4})
+	assert.Contains(t, texts[0], "uid: CHILI")
+	assert.NotContains(t, texts[0], "uid: SALAD")
+
+	// renamed categories are picked up by the next refresh
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Supper"})
+	s.refreshResources()
+	texts = callTool(t, s, "search_recipes", map[string]interface{}{"query": "supper"})
+	assert.Contains(t, texts[0], "uid: CHILI")
+
+	// trashed recipes disappear from the results
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili", InTrash: true})
+	s.refreshResources()
+	texts = callTool(t, s, "search_recipes", map[string]interface{}{"query": "beans"})
+	assert.NotContains(t, texts[0], "uid: CHILI")
+
+	texts = callTool(t, s, "search_recipes", map[string]interface{}{"query": "lasagna"})
+	assert.Equal(t, []string{"_No recipes found_\n"}, texts)
+}
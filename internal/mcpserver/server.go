@@ -5,44 +5,115 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/cache"
 	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/search"
 )
 
 type NewServerOptions struct {
 	Version  string
 	Username string
 	Password string
-	Paprika  *paprika.Client
+	// BaseURL overrides the Paprika API host; see paprika.NewClientOptions
+	BaseURL string
+	// Paprika is an already configured client. If set, Username, Password and BaseURL are ignored.
+	Paprika *paprika.Client
+	// CacheDir is the directory recipes are persisted to between runs. Caching is disabled if empty.
+	CacheDir string
 	Logger   *slog.Logger
 }
 
 func NewServer(opts NewServerOptions) (*Server, error) {
-	paprika3, err := paprika.NewClient(opts.Username, opts.Password, opts.Version, opts.Logger)
-	if err != nil {
-		return nil, err
+	paprika3 := opts.Paprika
+	if paprika3 == nil {
+		var err error
+		paprika3, err = paprika.NewClient(paprika.NewClientOptions{
+			Username: opts.Username,
+			Password: opts.Password,
+			Version:  opts.Version,
+			BaseURL:  opts.BaseURL,
+			// Don't block startup on Paprika, the first refresh logs in
+			DeferLogin: true,
+			Logger:     opts.Logger,
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	s := server.NewMCPServer("paprika-3-mcp", opts.Version, server.WithResourceCapabilities(false, false))
-	return &Server{
-		paprika3: paprika3,
-		server:   s,
-		logger:   opts.Logger,
-	}, nil
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var store *cache.Store
+	if opts.CacheDir != "" {
+		var err error
+		store, err = cache.Open(opts.CacheDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hooks := &server.Hooks{}
+	s := &Server{
+		paprika3:      paprika3,
+		server:        server.NewMCPServer("paprika-3-mcp", opts.Version, server.WithResourceCapabilities(true, true), server.WithHooks(hooks)),
+		logger:        logger,
+		library:       newLibrary(),
+		index:         search.NewIndex(),
+		cache:         store,
+		subscriptions: newSubscriptions(),
+		synced:        make(chan struct{}),
+	}
+	s.loadCache()
+	s.trackSessions(hooks)
+	s.addTools()
+	s.addRecipeResources(hooks)
+	s.addPantryResources()
+
+	return s, nil
 }
 
 type Server struct {
 	paprika3 *paprika.Client
 	logger   *slog.Logger
 	server   *server.MCPServer
+	library  *library
+	index    *search.Index
+	// cache is nil if caching is disabled
+	cache *cache.Store
+	// sessions holds the connected clients by session ID
+	sessions      sync.Map
+	subscriptions *subscriptions
+	// synced is closed once the library was loaded from the cache or the first refresh finished, successful or not
+	synced     chan struct{}
+	syncedOnce sync.Once
 }
 
 func (s *Server) Start() {
 	go s.updateResources()
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	if err := s.serveStdio(ctx, os.Stdin, os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Error("Server error", "err", err)
+	}
+}
+
+func (s *Server) addTools() {
 	createRecipeTool := mcp.NewTool("create_paprika_recipe",
 		mcp.WithDescription("Save new recipes generated by LLMs in the Paprika 3 app"),
 		mcp.WithString("name", mcp.Description("The name of the recipe"), mcp.Required()),
@@ -54,19 +125,32 @@ func (s *Server) Start() {
 		mcp.WithString("prep_time", mcp.Description("The prep time for the recipe"), mcp.DefaultString("")),
 		mcp.WithString("cook_time", mcp.Description("The cook time for the recipe"), mcp.DefaultString("")),
 		mcp.WithString("difficulty", mcp.Description("The difficulty of the recipe"), mcp.DefaultString("")),
+		mcp.WithArray("categories", mcp.Description("The names of the categories for the recipe; categories that don't exist yet are created"), mcp.Items(map[string]interface{}{"type": "string"})),
+		mcp.WithString("created", mcp.Description("When the recipe was originally created, as YYYY-MM-DD or YYYY-MM-DD HH:MM:SS, e.g. to keep the date of an imported recipe; defaults to now")),
 	)
 	updateRecipeTool := mcp.NewTool("update_paprika_recipe",
-		mcp.WithDescription("Update existing recipes in the Paprika 3 app"),
+		mcp.WithDescription("Update existing recipes in the Paprika 3 app. Only the fields that are passed are changed; everything else, including the photo, is kept."),
 		mcp.WithString("uid", mcp.Description("The UID of the recipe"), mcp.Required()),
-		mcp.WithString("name", mcp.Description("The name of the recipe"), mcp.Required()),
-		mcp.WithString("ingredients", mcp.Description("The ingredients of the recipe"), mcp.Required()),
-		mcp.WithString("directions", mcp.Description("The directions for the recipe"), mcp.Required()),
-		mcp.WithString("description", mcp.Description("The description of the recipe"), mcp.Required()),
-		mcp.WithString("notes", mcp.Description("The notes for the recipe"), mcp.Required()),
-		mcp.WithString("servings", mcp.Description("The number of servings for the recipe"), mcp.Required()),
-		mcp.WithString("prep_time", mcp.Description("The prep time for the recipe"), mcp.Required()),
-		mcp.WithString("cook_time", mcp.Description("The cook time for the recipe"), mcp.Required()),
-		mcp.WithString("difficulty", mcp.Description("The difficulty of the recipe"), mcp.Required()),
+		mcp.WithString("name", mcp.Description("The name of the recipe")),
+		mcp.WithString("ingredients", mcp.Description("The ingredients of the recipe")),
+		mcp.WithString("directions", mcp.Description("The directions for the recipe")),
+		mcp.WithString("description", mcp.Description("The description of the recipe")),
+		mcp.WithString("notes", mcp.Description("The notes for the recipe")),
+		mcp.WithString("servings", mcp.Description("The number of servings for the recipe")),
+		mcp.WithString("prep_time", mcp.Description("The prep time for the recipe")),
+		mcp.WithString("cook_time", mcp.Description("The cook time for the recipe")),
+		mcp.WithString("total_time", mcp.Description("The total time for the recipe")),
+		mcp.WithString("difficulty", mcp.Description("The difficulty of the recipe")),
+		mcp.WithString("source", mcp.Description("Where the recipe is from, e.g. a cookbook or website name")),
+		mcp.WithString("source_url", mcp.Description("The URL the recipe is from")),
+		mcp.WithString("nutritional_info", mcp.Description("The nutritional information of the recipe")),
+		mcp.WithNumber("rating", mcp.Description("The rating of the recipe, from 0 to 5 stars")),
+		mcp.WithBoolean("favorite", mcp.Description("Whether the recipe is a favourite")),
+		mcp.WithArray("categories", mcp.Description("The names of the categories for the recipe, replacing the current ones; categories that don't exist yet are created"), mcp.Items(map[string]interface{}{"type": "string"})),
+		mcp.WithString("expected_hash", mcp.Description("The hash of the recipe when you read it, e.g. from get_paprika_recipe. The update is rejected if the recipe was changed since.")),
+	)
+	listCategoriesTool := mcp.NewTool("list_paprika_categories",
+		mcp.WithDescription("List the names of the recipe categories in the Paprika 3 app"),
 	)
 	s.server.AddTools(server.ServerTool{
 		Tool:    createRecipeTool,
@@ -74,94 +158,238 @@ func (s *Server) Start() {
 	}, server.ServerTool{
 		Tool:    updateRecipeTool,
 		Handler: s.updateRecipe,
+	}, server.ServerTool{
+		Tool:    listCategoriesTool,
+		Handler: s.listCategories,
 	})
+	s.server.AddTools(s.recipeTools()...)
+	s.server.AddTools(s.searchTools()...)
+	s.server.AddTools(s.summaryTools()...)
+	s.server.AddTools(s.scaleTools()...)
+	s.server.AddTools(s.unitTools()...)
+	s.server.AddTools(s.groceryTools()...)
+	s.server.AddTools(s.shoppingTools()...)
+	s.server.AddTools(s.archiveTools()...)
+	s.server.AddTools(s.schemaOrgTools()...)
+	s.server.AddTools(s.cooklangTools()...)
+	s.server.AddTools(s.mealTools()...)
+	s.server.AddTools(s.pantryTools()...)
+}
 
-	if err := server.ServeStdio(s.server); err != nil {
-		s.logger.Error("Server error", "err", err)
+const recipeURIPrefix = "paprika://recipes/"
+
+func recipeURI(uid string) string {
+	return recipeURIPrefix + uid
+}
+
+// addRecipeResources exposes the recipes in the library as resources. mcp-go can't unregister
+// resources, so instead of adding one resource per recipe they are listed from the library
+// by a hook and read through a resource template.
+func (s *Server) addRecipeResources(hooks *server.Hooks) {
+	hooks.AddAfterListResources(func(ctx context.Context, id any, message *mcp.ListResourcesRequest, result *mcp.ListResourcesResult) {
+		for _, recipe := range s.library.all() {
+			result.Resources = append(result.Resources, mcp.NewResource(recipeURI(recipe.UID), recipe.Name, mcp.WithResourceDescription(recipe.ResourceDescription()), mcp.WithMIMEType("text/markdown")))
+		}
+	})
+
+	s.server.AddResourceTemplate(mcp.NewResourceTemplate(recipeURIPrefix+"{uid}", "Paprika recipe",
+		mcp.WithTemplateDescription("A recipe from the Paprika 3 app, rendered as markdown"),
+		mcp.WithTemplateMIMEType("text/markdown"),
+	), s.readRecipeResource)
+}
+
+func (s *Server) readRecipeResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uid := strings.TrimPrefix(request.Params.URI, recipeURIPrefix)
+	recipe, categories, ok := s.library.get(uid)
+	if !ok {
+		return nil, fmt.Errorf("recipe %s not found", uid)
 	}
+
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      recipeURI(recipe.UID),
+		MIMEType: "text/markdown",
+		Text:     recipe.ToMarkdown(paprika.WithCategories(categories)),
+	}}, nil
 }
 
 func (s *Server) updateResources() {
-	s.addResources()
+	s.refreshResources()
 
 	ticker := time.NewTicker(1 * time.Minute)
 	for range ticker.C {
-		s.addResources()
+		s.refreshResources()
 	}
 }
 
-func (s *Server) addResources() {
-	s.logger.Info("Updating recipe resources")
+// refreshResources brings the library up to date with Paprika. Only recipes whose hash changed
+// since the last refresh are downloaded, and recipes that were deleted or trashed are removed.
+func (s *Server) refreshResources() changes {
+	defer s.syncedOnce.Do(func() { close(s.synced) })
+
+	start := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	// List all recipes to expose them as resources
+	// List all recipes to find out which ones changed
 	recipes, err := s.paprika3.ListRecipes(ctx)
 	if err != nil {
 		s.logger.Error("failed to list paprika recipes", "err", err)
-		return
+		return changes{}
 	}
 
-	if len(recipes.Result) >= 10 {
-		s.logger.Info("adding recipes resources concurrently")
-		s.addResourcesConcurrently(recipes)
-		return
+	// Categories are only used to render names, so recipes are still exposed if they can't be listed
+	categories, err := s.paprika3.ListCategories(ctx)
+	if err != nil {
+		s.logger.Error("failed to list paprika categories", "err", err)
+	} else {
+		if s.library.setCategories(categories) {
+			// category names are searchable
+			s.reindex()
+		}
+		if s.cache != nil {
+			if err := s.cache.PutCategories(categories); err != nil {
+				s.logger.Error("failed to cache categories", "err", err)
+			}
+		}
 	}
 
-	for _, r := range recipes.Result {
-		if err := s.addRecipeResource(r.UID); err != nil {
-			s.logger.Error("failed to add recipe as MCP resource", "err", err)
+	stale, gone := s.library.diff(recipes)
+	var result changes
+	result.unchanged = len(recipes.Result) - len(stale)
+
+	for _, uid := range gone {
+		if s.forgetRecipe(uid) {
+			result.removed = append(result.removed, uid)
 		}
 	}
+
+	var mu sync.Mutex
+	s.fetchRecipes(recipes, stale, func(recipe *paprika.Recipe, hash string) {
+		// Paprika doesn't say when a recipe was modified, so changes are dated when they are
+		// first seen, and recipes that are new to the library by their created date
+		modified := time.Now()
+		if created, ok := recipe.CreatedAt(); ok && !s.library.known(recipe.UID) {
+			modified = created
+		}
+
+		added, updated, removed := s.storeRecipe(recipe, hash, modified)
+
+		mu.Lock()
+		defer mu.Unlock()
+		result.record(recipe.UID, added, updated, removed)
+	})
+
+	s.logger.Info("Refreshed recipe resources",
+		"added", len(result.added),
+		"updated", len(result.updated),
+		"removed", len(result.removed),
+		"unchanged", result.unchanged,
+		"duration", time.Since(start),
+	)
+	s.notifyResourceChanges(result)
+
+	return result
 }
 
-func (s *Server) addRecipeResource(uid string) error {
-	start := time.Now()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	recipe, err := s.paprika3.GetRecipe(ctx, uid)
-	if err != nil {
-		return err
+// awaitLibrary waits for the first refresh, unless the library was already loaded from the cache,
+// so tools that read the library don't come up empty right after startup
+func (s *Server) awaitLibrary(ctx context.Context) error {
+	select {
+	case <-s.synced:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("recipe library is still loading: %w", ctx.Err())
 	}
+}
 
-	if recipe.InTrash {
-		return nil
+// loadCache fills the library with the recipes cached by a previous run,
+// so resources are available before the first refresh completes
+func (s *Server) loadCache() {
+	if s.cache == nil {
+		return
 	}
 
-	resourceContents := mcp.TextResourceContents{
-		URI:      fmt.Sprintf("paprika://recipes/%s", recipe.UID),
-		MIMEType: "text/markdown",
-		Text:     recipe.ToMarkdown(),
+	entries, err := s.cache.Recipes()
+	if err != nil {
+		s.logger.Error("failed to load cached recipes", "dir", s.cache.Dir(), "err", err)
+		return
+	}
+	for _, entry := range entries {
+		s.library.put(&entry.Recipe, entry.Hash, entry.Modified)
 	}
 
-	s.server.AddResource(mcp.NewResource(fmt.Sprintf("paprika://recipes/%s", recipe.UID), recipe.Name, mcp.WithResourceDescription(recipe.ResourceDescription()), mcp.WithMIMEType("text/markdown")), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-		return []mcp.ResourceContents{resourceContents}, nil
-	})
+	categories, err := s.cache.Categories()
+	if err != nil {
+		s.logger.Error("failed to load cached categories", "dir", s.cache.Dir(), "err", err)
+	} else {
+		s.library.setCategories(categories)
+	}
+	s.reindex()
+	if len(entries) > 0 {
+		s.syncedOnce.Do(func() { close(s.synced) })
+	}
 
-	duration := time.Since(start)
-	s.logger.Info("Added resource", "name", recipe.Name, "uid", recipe.UID, "duration", duration)
+	s.logger.Info("Loaded cached recipes", "count", len(entries), "dir", s.cache.Dir())
+}
 
-	return nil
+// storeRecipe puts a recipe in the library and the search index, and persists it to the cache
+func (s *Server) storeRecipe(recipe *paprika.Recipe, hash string, modified time.Time) (added, updated, removed bool) {
+	added, updated, removed = s.library.put(recipe, hash, modified)
+	if recipe.InTrash {
+		s.index.Remove(recipe.UID)
+	} else {
+		s.index.Add(recipe, s.library.categoryNames(recipe.Categories))
+	}
+	if s.cache != nil {
+		if err := s.cache.PutRecipe(recipe, hash, modified); err != nil {
+			s.logger.Error("failed to cache recipe", "uid", recipe.UID, "err", err)
+		}
+	}
+	return added, updated, removed
 }
 
-func (s *Server) addResourcesConcurrently(recipes *paprika.RecipeList) {
-	buffer := make(chan struct{}, 10)
+// forgetRecipe removes a recipe from the library and the cache, reporting whether it was exposed as a resource
+func (s *Server) forgetRecipe(uid string) bool {
+	removed := s.library.remove(uid)
+	s.index.Remove(uid)
+	if s.cache != nil {
+		if err := s.cache.RemoveRecipe(uid); err != nil {
+			s.logger.Error("failed to remove cached recipe", "uid", uid, "err", err)
+		}
+	}
+	return removed
+}
+
+// fetchRecipes downloads the given recipes, at most 10 at a time, and passes each one to store
+// along with the hash it was listed with. Recipes that fail to download are retried on the next refresh.
+func (s *Server) fetchRecipes(list *paprika.RecipeList, uids []string, store func(recipe *paprika.Recipe, hash string)) {
+	hashes := make(map[string]string, len(list.Result))
+	for _, entry := range list.Result {
+		hashes[entry.UID] = entry.Hash
+	}
 
-	for _, r := range recipes.Result {
-		go func() (err error) {
+	buffer := make(chan struct{}, 10)
+	var wg sync.WaitGroup
+	for _, uid := range uids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 			buffer <- struct{}{}
 			defer func() {
 				<-buffer
 			}()
 
-			defer func(err error) {
-				if err != nil {
-					s.logger.Error("failed to add recipe as MCP resource", "err", err)
-				}
-			}(err)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			recipe, err := s.paprika3.GetRecipe(ctx, uid)
+			if err != nil {
+				s.logger.Error("failed to fetch recipe", "uid", uid, "err", err)
+				return
+			}
 
-			return s.addRecipeResource(r.UID)
+			store(recipe, hashes[uid])
 		}()
 	}
+	wg.Wait()
 }
 
 func (s *Server) createRecipe(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -178,15 +406,28 @@ func (s *Server) createRecipe(ctx context.Context, req mcp.CallToolRequest) (*mc
 	if !ok || len(directions) == 0 {
 		return nil, errors.New("directions are required")
 	}
-	servings := req.Params.Arguments["servings"].(string)
-	prepTime := req.Params.Arguments["prep_time"].(string)
-	cookTime := req.Params.Arguments["cook_time"].(string)
-	description := req.Params.Arguments["description"].(string)
-	notes := req.Params.Arguments["notes"].(string)
-	difficulty := req.Params.Arguments["difficulty"].(string)
+	servings := stringArgument(req.Params.Arguments, "servings")
+	prepTime := stringArgument(req.Params.Arguments, "prep_time")
+	cookTime := stringArgument(req.Params.Arguments, "cook_time")
+	description := stringArgument(req.Params.Arguments, "description")
+	notes := stringArgument(req.Params.Arguments, "notes")
+	difficulty := stringArgument(req.Params.Arguments, "difficulty")
+	categoryNames, err := stringSliceArgument(req.Params.Arguments, "categories")
+	if err != nil {
+		return nil, err
+	}
+	created, err := createdArgument(req.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
+	categories, err := s.resolveCategories(ctx, categoryNames)
+	if err != nil {
+		return nil, err
+	}
+
 	recipe, err := s.paprika3.SaveRecipe(ctx, paprika.Recipe{
 		Name:        name,
 		Ingredients: ingredients,
@@ -197,88 +438,195 @@ func (s *Server) createRecipe(ctx context.Context, req mcp.CallToolRequest) (*mc
 		CookTime:    cookTime,
 		Notes:       notes,
 		Difficulty:  difficulty,
+		Categories:  categories,
+		Created:     created,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	var c changes
+	added, updated, removed := s.storeRecipe(recipe, recipe.Hash, time.Now())
+	c.record(recipe.UID, added, updated, removed)
+	s.notifyResourceChanges(c)
+
 	duration := time.Since(start)
 	s.logger.Info("Created recipe", "name", recipe.Name, "uid", recipe.UID, "duration", duration)
 
-	return mcp.NewToolResultResource(recipe.Name, mcp.TextResourceContents{
-		URI:      fmt.Sprintf("paprika://recipes/%s", recipe.UID),
-		MIMEType: "text/markdown",
-		Text:     recipe.ToMarkdown(),
-	}), nil
+	return s.recipeResult(ctx, recipe), nil
 }
 
 func (s *Server) updateRecipe(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	start := time.Now()
-	uid, ok := req.Params.Arguments["uid"].(string)
+	args := req.Params.Arguments
+	uid, ok := args["uid"].(string)
 	if !ok || len(uid) == 0 {
 		return nil, errors.New("uid is required")
 	}
-	name, ok := req.Params.Arguments["name"].(string)
-	if !ok || len(name) == 0 {
-		return nil, errors.New("name is required")
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := s.paprika3.GetRecipe(ctx, uid)
+	if err != nil {
+		return nil, err
 	}
-	ingredients, ok := req.Params.Arguments["ingredients"].(string)
-	if !ok || len(ingredients) == 0 {
-		return nil, errors.New("ingredients are required")
+	// Reject the update if the recipe changed since the caller read it
+	if hash := stringArgument(args, "expected_hash"); hash != "" && hash != recipe.Hash {
+		return nil, conflictError(&paprika.ConflictError{UID: recipe.UID, ExpectedHash: hash, ActualHash: recipe.Hash})
 	}
-	directions, ok := req.Params.Arguments["directions"].(string)
-	if !ok || len(directions) == 0 {
-		return nil, errors.New("directions are required")
+	// Without a hash from the caller, at least guard against edits made while this update runs
+	expectedHash := recipe.Hash
+
+	// Apply only the fields that were passed, so fields the tool doesn't know about survive
+	fields := []struct {
+		name  string
+		field *string
+	}{
+		{"name", &recipe.Name},
+		{"ingredients", &recipe.Ingredients},
+		{"directions", &recipe.Directions},
+		{"description", &recipe.Description},
+		{"notes", &recipe.Notes},
+		{"servings", &recipe.Servings},
+		{"prep_time", &recipe.PrepTime},
+		{"cook_time", &recipe.CookTime},
+		{"total_time", &recipe.TotalTime},
+		{"difficulty", &recipe.Difficulty},
+		{"source", &recipe.Source},
+		{"source_url", &recipe.SourceURL},
+		{"nutritional_info", &recipe.NutritionalInfo},
 	}
-	description, ok := req.Params.Arguments["description"].(string)
-	if !ok {
-		return nil, errors.New("description is required")
+	var updated []string
+	for _, f := range fields {
+		if value, ok := args[f.name].(string); ok {
+			*f.field = value
+			updated = append(updated, f.name)
+		}
 	}
-	servings, ok := req.Params.Arguments["servings"].(string)
-	if !ok {
-		return nil, errors.New("servings is required")
+	if value, ok := args["rating"]; ok {
+		// JSON numbers are decoded as float64
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("rating must be a number, got %v", value)
+		}
+		if number != math.Trunc(number) {
+			return nil, fmt.Errorf("rating must be a whole number, got %v", number)
+		}
+		if number < 0 || number > 5 {
+			return nil, errors.New("rating must be between 0 and 5")
+		}
+		recipe.Rating = int(number)
+		updated = append(updated, "rating")
 	}
-	prepTime, ok := req.Params.Arguments["prep_time"].(string)
-	if !ok {
-		return nil, errors.New("prepTime is required")
+	if _, ok := args["favorite"]; ok {
+		recipe.OnFavorites = boolArgument(args, "favorite", recipe.OnFavorites)
+		updated = append(updated, "favorite")
 	}
-	cookTime, ok := req.Params.Arguments["cook_time"].(string)
-	if !ok {
-		return nil, errors.New("cookTime is required")
+	if _, ok := args["categories"]; ok {
+		categoryNames, err := stringSliceArgument(args, "categories")
+		if err != nil {
+			return nil, err
+		}
+		recipe.Categories, err = s.resolveCategories(ctx, categoryNames)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, "categories")
 	}
-	notes, ok := req.Params.Arguments["notes"].(string)
-	if !ok {
-		return nil, errors.New("notes is required")
+
+	if len(updated) == 0 {
+		return nil, errors.New("at least one field to update is required")
 	}
-	difficulty, ok := req.Params.Arguments["difficulty"].(string)
-	if !ok {
-		return nil, errors.New("difficulty is required")
+	if strings.TrimSpace(recipe.Name) == "" {
+		return nil, errors.New("name must not be empty")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-	recipe, err := s.paprika3.SaveRecipe(ctx, paprika.Recipe{
-		UID:         uid,
-		Name:        name,
-		Ingredients: ingredients,
-		Directions:  directions,
-		Description: description,
-		Servings:    servings,
-		PrepTime:    prepTime,
-		CookTime:    cookTime,
-		Notes:       notes,
-		Difficulty:  difficulty,
-	})
+	recipe, err = s.paprika3.SaveRecipe(ctx, *recipe, paprika.WithExpectedHash(expectedHash))
 	if err != nil {
-		return nil, err
+		return nil, conflictError(err)
 	}
 
+	var c changes
+	added, changed, removed := s.storeRecipe(recipe, recipe.Hash, time.Now())
+	c.record(recipe.UID, added, changed, removed)
+	s.notifyResourceChanges(c)
+
 	duration := time.Since(start)
-	s.logger.Info("Updated recipe", "name", recipe.Name, "uid", recipe.UID, "duration", duration)
+	s.logger.Info("Updated recipe", "name", recipe.Name, "uid", recipe.UID, "fields", updated, "duration", duration)
+
+	return s.recipeResult(ctx, recipe), nil
+}
+
+// conflictError explains a *paprika.ConflictError in terms an assistant can act on. Other errors are returned as is.
+func conflictError(err error) error {
+	var conflict *paprika.ConflictError
+	if !errors.As(err, &conflict) {
+		return err
+	}
+
+	return fmt.Errorf("%w. It was probably edited in the Paprika app; get the recipe again and reapply your changes to the current version", conflict)
+}
 
+// resolveCategories translates category names passed to a tool into category UIDs
+func (s *Server) resolveCategories(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return []string{}, nil
+	}
+
+	uids, err := s.paprika3.ResolveCategories(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve categories: %w", err)
+	}
+
+	return uids, nil
+}
+
+// recipeResult renders a recipe as the embedded markdown resource returned by recipe tools
+func (s *Server) recipeResult(ctx context.Context, recipe *paprika.Recipe) *mcp.CallToolResult {
+	return recipeResultWithCategories(recipe, s.recipeCategories(ctx, recipe))
+}
+
+// recipeResultWithCategories is recipeResult for callers that already have the categories
+func recipeResultWithCategories(recipe *paprika.Recipe, categories paprika.Categories) *mcp.CallToolResult {
 	return mcp.NewToolResultResource(recipe.Name, mcp.TextResourceContents{
-		URI:      fmt.Sprintf("paprika://recipes/%s", recipe.UID),
+		URI:      recipeURI(recipe.UID),
 		MIMEType: "text/markdown",
-		Text:     recipe.ToMarkdown(),
-	}), nil
+		Text:     recipe.ToMarkdown(paprika.WithCategories(categories)),
+	})
+}
+
+// recipeCategories returns the categories needed to render the names of a recipe's categories.
+// The library's copy is used if Paprika can't be reached.
+func (s *Server) recipeCategories(ctx context.Context, recipe *paprika.Recipe) paprika.Categories {
+	if len(recipe.Categories) == 0 {
+		return nil
+	}
+
+	categories, err := s.paprika3.ListCategories(ctx)
+	if err != nil {
+		s.logger.Error("failed to list paprika categories", "err", err)
+		_, categories, _ = s.library.get(recipe.UID)
+	}
+	return categories
+}
+
+func (s *Server) listCategories(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	categories, err := s.paprika3.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return mcp.NewToolResultText("There are no categories in Paprika yet"), nil
+	}
+
+	return mcp.NewToolResultText(strings.Join(names, "\n")), nil
 }
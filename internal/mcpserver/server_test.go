@@ -0,0 +1,486 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) (*Server, *paprikatest.Server) {
+	t.Helper()
+
+	fake := paprikatest.NewServer()
+	t.Cleanup(fake.Close)
+
+	client, err := fake.NewClient()
+	require.NoError(t, err)
+
+	s, err := NewServer(NewServerOptions{
+		Version: "test",
+		Paprika: client,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	require.NoError(t, err)
+
+	return s, fake
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// rpc sends a JSON-RPC request through the MCP server and returns the raw response
+func rpc(t *testing.T, s *Server, method string, params interface{}) rpcResponse {
+	t.Helper()
+
+	msg, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(s.handleMessage(context.Background(), msg))
+	require.NoError(t, err)
+
+	var resp rpcResponse
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	return resp
+}
+
+// callTool calls the named tool and returns the text of each content item
+func callTool(t *testing.T, s *Server, name string, args map[string]interface{}) []string {
+	t.Helper()
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+	require.Nil(t, resp.Error, "tool %s returned an error", name)
+
+	var result struct {
+		Content []struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			Resource struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"resource"`
+		} `json:"content"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+
+	texts := make([]string, 0, len(result.Content))
+	for _, c := range result.Content {
+		if c.Type == "resource" {
+			texts = append(texts, c.Resource.Text)
+			continue
+		}
+		texts = append(texts, c.Text)
+	}
+	return texts
+}
+
+// listRecipeResources returns the URIs of the listed recipe resources, sorted
+func listRecipeResources(t *testing.T, s *Server) []string {
+	t.Helper()
+
+	resp := rpc(t, s, "resources/list", map[string]interface{}{})
+	require.Nil(t, resp.Error)
+
+	var result mcp.ListResourcesResult
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+
+	var uris []string
+	for _, resource := range result.Resources {
+		if strings.HasPrefix(resource.URI, "paprika://recipes/") {
+			uris = append(uris, resource.URI)
+		}
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+func TestCreateAndUpdateRecipe(t *testing.T) {
+	s, fake := newTestServer(t)
+
+	texts := callTool(t, s, "create_paprika_recipe", map[string]interface{}{
+		"name":        "Pancakes",
+		"ingredients": "1 cup flour\n1 egg\n1 cup milk",
+		"directions":  "Mix\nFry",
+		"description": "Fluffy",
+		"notes":       "",
+		"servings":    "2",
+		"prep_time":   "5 mins",
+		"cook_time":   "10 mins",
+		"difficulty":  "Easy",
+	})
+	require.Len(t, texts, 2)
+	assert.Contains(t, texts[1], "# Pancakes")
+
+	recipes := fake.Recipes()
+	require.Len(t, recipes, 1)
+	assert.Equal(t, "Pancakes", recipes[0].Name)
+
+	callTool(t, s, "update_paprika_recipe", map[string]interface{}{
+		"uid":         recipes[0].UID,
+		"name":        "Buttermilk Pancakes",
+		"ingredients": "1 cup flour\n1 egg\n1 cup buttermilk",
+		"directions":  "Mix\nFry",
+		"description": "Fluffy",
+		"notes":       "",
+		"servings":    "2",
+		"prep_time":   "5 mins",
+		"cook_time":   "10 mins",
+		"difficulty":  "Easy",
+	})
+
+	updated, ok := fake.Recipe(recipes[0].UID)
+	require.True(t, ok)
+	assert.Equal(t, "Buttermilk Pancakes", updated.Name)
+}
+
+func TestCreateRecipeWithCreatedDate(t *testing.T) {
+	s, fake := newTestServer(t)
+
+	callTool(t, s, "create_paprika_recipe", map[string]interface{}{
+		"name":        "Grandma's Stew",
+		"ingredients": "beef",
+		"directions":  "simmer",
+		"created":     "2019-11-24",
+	})
+
+	recipes := fake.Recipes()
+	require.Len(t, recipes, 1)
+	assert.Equal(t, "2019-11-24 00:00:00", recipes[0].Created)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "create_paprika_recipe",
+		"arguments": map[string]interface{}{"name": "Soup", "ingredients": "water", "directions": "boil", "created": "last week"},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "invalid created date")
+}
+
+func TestCreateRecipeRequiresName(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "create_paprika_recipe",
+		"arguments": map[string]interface{}{"ingredients": "salt", "directions": "season"},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "name is required")
+}
+
+func TestRecipeResources(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", Ingredients: "water"})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Old Soup", InTrash: true})
+
+	changes := s.refreshResources()
+	assert.Equal(t, []string{"A"}, changes.added)
+
+	assert.Equal(t, []string{"paprika://recipes/A"}, listRecipeResources(t, s))
+
+	resp := rpc(t, s, "resources/read", map[string]interface{}{"uri": "paprika://recipes/A"})
+	require.Nil(t, resp.Error)
+	assert.Contains(t, string(resp.Result), "# Soup")
+
+	resp = rpc(t, s, "resources/read", map[string]interface{}{"uri": "paprika://recipes/B"})
+	require.NotNil(t, resp.Error)
+}
+
+func TestRefreshResourcesSkipsUnchangedRecipes(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", Ingredients: "water", Hash: "v1"})
+	s.refreshResources()
+
+	// same hash, different content: the recipe must not be downloaded again
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Stew", Ingredients: "water", Hash: "v1"})
+	changes := s.refreshResources()
+	assert.True(t, changes.empty())
+	assert.Equal(t, 1, changes.unchanged)
+
+	resp := rpc(t, s, "resources/read", map[string]interface{}{"uri": "paprika://recipes/A"})
+	require.Nil(t, resp.Error)
+	assert.Contains(t, string(resp.Result), "# Soup")
+
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Stew", Ingredients: "water", Hash: "v2"})
+	changes = s.refreshResources()
+	assert.Equal(t, []string{"A"}, changes.updated)
+
+	resp = rpc(t, s, "resources/read", map[string]interface{}{"uri": "paprika://recipes/A"})
+	require.Nil(t, resp.Error)
+	assert.Contains(t, string(resp.Result), "# Stew")
+}
+
+func TestRefreshResourcesRemovesRecipes(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup"})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Salad"})
+	s.refreshResources()
+	assert.Equal(t, []string{"paprika://recipes/A", "paprika://recipes/B"}, listRecipeResources(t, s))
+
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", InTrash: true})
+	fake.RemoveRecipe("B")
+	changes := s.refreshResources()
+	assert.ElementsMatch(t, []string{"A", "B"}, changes.removed)
+	assert.Empty(t, listRecipeResources(t, s))
+}
+
+func TestRefreshResourcesRetriesFailedDownloads(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup"})
+	fake.InjectFailure(paprikatest.Failure{Method: "GET", Path: "/api/v2/sync/recipe/", Status: 500})
+
+	changes := s.refreshResources()
+	assert.True(t, changes.empty())
+	assert.Empty(t, listRecipeResources(t, s))
+
+	changes = s.refreshResources()
+	assert.Equal(t, []string{"A"}, changes.added)
+}
+
+func TestCreatedRecipeIsExposedImmediately(t *testing.T) {
+	s, fake := newTestServer(t)
+
+	callTool(t, s, "create_paprika_recipe", map[string]interface{}{
+		"name":        "Toast",
+		"ingredients": "bread",
+		"directions":  "toast",
+		"description": "",
+		"notes":       "",
+		"servings":    "",
+		"prep_time":   "",
+		"cook_time":   "",
+		"difficulty":  "",
+	})
+	recipes := fake.Recipes()
+	require.Len(t, recipes, 1)
+	assert.Equal(t, []string{"paprika://recipes/" + recipes[0].UID}, listRecipeResources(t, s))
+
+	// the next refresh already knows the recipe
+	changes := s.refreshResources()
+	assert.True(t, changes.empty())
+}
+
+func TestCreateRecipeWithCategories(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+
+	texts := callTool(t, s, "create_paprika_recipe", map[string]interface{}{
+		"name":        "Chili",
+		"ingredients": "beans",
+		"directions":  "simmer",
+		"description": "",
+		"notes":       "",
+		"servings":    "",
+		"prep_time":   "",
+		"cook_time":   "",
+		"difficulty":  "",
+		"categories":  []string{"dinner", "Vegetarian"},
+	})
+	require.Len(t, texts, 2)
+	assert.Contains(t, texts[1], "- **Categories:** Dinner, Vegetarian")
+
+	recipes := fake.Recipes()
+	require.Len(t, recipes, 1)
+	require.Len(t, recipes[0].Categories, 2)
+	assert.Equal(t, "DINNER", recipes[0].Categories[0])
+
+	texts = callTool(t, s, "list_paprika_categories", map[string]interface{}{})
+	assert.Equal(t, []string{"Dinner\nVegetarian"}, texts)
+}
+
+func TestCachedRecipesAreServedOffline(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	fake := paprikatest.NewServer()
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", Categories: []string{"DINNER"}})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Old Soup", InTrash: true})
+
+	s, err := NewServer(NewServerOptions{
+		Version:  "test",
+		Username: paprikatest.Username,
+		Password: paprikatest.Password,
+		BaseURL:  fake.URL,
+		CacheDir: dir,
+		Logger:   logger,
+	})
+	require.NoError(t, err)
+	s.refreshResources()
+	fake.Close()
+
+	// Paprika is unreachable now, but the server still starts with the cached library
+	s, err = NewServer(NewServerOptions{
+		Version:  "test",
+		Username: paprikatest.Username,
+		Password: paprikatest.Password,
+		BaseURL:  fake.URL,
+		CacheDir: dir,
+		Logger:   logger,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"paprika://recipes/A"}, listRecipeResources(t, s))
+
+	changes := s.refreshResources()
+	assert.True(t, changes.empty())
+	assert.Equal(t, []string{"paprika://recipes/A"}, listRecipeResources(t, s))
+
+	resp := rpc(t, s, "resources/read", map[string]interface{}{"uri": "paprika://recipes/A"})
+	require.Nil(t, resp.Error)
+	assert.Contains(t, string(resp.Result), "**Categories:** Dinner")
+}
+
+func TestCachedRecipesAreNotRefetched(t *testing.T) {
+	dir := t.TempDir()
+	fake := paprikatest.NewServer()
+	t.Cleanup(fake.Close)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", Hash: "v1"})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Salad"})
+
+	newServer := func() *Server {
+		client, err := fake.NewClient()
+		require.NoError(t, err)
+		s, err := NewServer(NewServerOptions{
+			Version:  "test",
+			Paprika:  client,
+			CacheDir: dir,
+			Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		})
+		require.NoError(t, err)
+		return s
+	}
+
+	newServer().refreshResources()
+	fake.RemoveRecipe("B")
+
+	changes := newServer().refreshResources()
+	assert.Equal(t, 1, changes.unchanged)
+	assert.Equal(t, []string{"B"}, changes.removed)
+
+	// B is gone from the cache too
+	assert.Equal(t, []string{"paprika://recipes/A"}, listRecipeResources(t, newServer()))
+}
+
+func TestUpdateRecipeOnlyChangesSuppliedFields(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	fake.PutRecipe(paprika.Recipe{
+		UID:             "CHILI",
+		Name:            "Chili",
+		Ingredients:     "beans",
+		Directions:      "Simmer\nServe wiht rice",
+		Notes:           "Spicy",
+		Categories:      []string{"DINNER"},
+		Rating:          4,
+		OnFavorites:     true,
+		Photo:           "chili.jpg",
+		PhotoHash:       "abc",
+		Source:          "Grandma",
+		SourceURL:       "https://example.com/chili",
+		NutritionalInfo: "400 kcal",
+	})
+
+	texts := callTool(t, s, "update_paprika_recipe", map[string]interface{}{
+		"uid":        "CHILI",
+		"directions": "Simmer\nServe with rice",
+	})
+	require.Len(t, texts, 2)
+	assert.Contains(t, texts[1], "2. Serve with rice")
+
+	recipe, ok := fake.Recipe("CHILI")
+	require.True(t, ok)
+	assert.Equal(t, "Simmer\nServe with rice", recipe.Directions)
+	assert.Equal(t, "Chili", recipe.Name)
+	assert.Equal(t, "beans", recipe.Ingredients)
+	assert.Equal(t, "Spicy", recipe.Notes)
+	assert.Equal(t, []string{"DINNER"}, recipe.Categories)
+	assert.Equal(t, 4, recipe.Rating)
+	assert.True(t, recipe.OnFavorites)
+	assert.Equal(t, "chili.jpg", recipe.Photo)
+	assert.Equal(t, "abc", recipe.PhotoHash)
+	assert.Equal(t, "Grandma", recipe.Source)
+	assert.Equal(t, "https://example.com/chili", recipe.SourceURL)
+	assert.Equal(t, "400 kcal", recipe.NutritionalInfo)
+
+	// empty strings clear a field, and the extra fields can be changed too
+	callTool(t, s, "update_paprika_recipe", map[string]interface{}{
+		"uid":        "CHILI",
+		"notes":      "",
+		"rating":     5,
+		"favorite":   false,
+		"categories": []string{},
+	})
+	recipe, _ = fake.Recipe("CHILI")
+	assert.Empty(t, recipe.Notes)
+	assert.Equal(t, 5, recipe.Rating)
+	assert.False(t, recipe.OnFavorites)
+	assert.Empty(t, recipe.Categories)
+	assert.Equal(t, "chili.jpg", recipe.Photo)
+}
+
+func TestUpdateRecipeValidation(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili"})
+
+	for message, args := range map[string]map[string]interface{}{
+		"at least one field to update is required": {"uid": "CHILI"},
+		"name must not be empty":                   {"uid": "CHILI", "name": " "},
+		"rating must be between 0 and 5":           {"uid": "CHILI", "rating": 6},
+		"rating must be a number":                  {"uid": "CHILI", "rating": "4"},
+		"rating must be a whole number":            {"uid": "CHILI", "rating": 4.7},
+	} {
+		resp := rpc(t, s, "tools/call", map[string]interface{}{"name": "update_paprika_recipe", "arguments": args})
+		require.NotNil(t, resp.Error)
+		assert.Contains(t, resp.Error.Message, message)
+	}
+	recipe, ok := fake.Recipe("CHILI")
+	require.True(t, ok)
+	assert.Zero(t, recipe.Rating)
+}
+
+func TestUpdateRecipeConflict(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili", Notes: "Spicy"})
+
+	texts := callTool(t, s, "get_paprika_recipe", map[string]interface{}{"uid": "CHILI"})
+	var read paprika.Recipe
+	require.NoError(t, json.Unmarshal([]byte(texts[2]), &read))
+	require.NotEmpty(t, read.Hash)
+
+	// edited on the phone in the meantime
+	fake.PutRecipe(paprika.Recipe{UID: "CHILI", Name: "Chili", Notes: "Very spicy"})
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "update_paprika_recipe",
+		"arguments": map[string]interface{}{"uid": "CHILI", "notes": "Mild", "expected_hash": read.Hash},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "recipe CHILI was changed since it was read")
+	assert.Contains(t, resp.Error.Message, "get the recipe again")
+
+	recipe, _ := fake.Recipe("CHILI")
+	assert.Equal(t, "Very spicy", recipe.Notes)
+
+	texts = callTool(t, s, "get_paprika_recipe", map[string]interface{}{"uid": "CHILI"})
+	require.NoError(t, json.Unmarshal([]byte(texts[2]), &read))
+	callTool(t, s, "update_paprika_recipe", map[string]interface{}{"uid": "CHILI", "notes": "Mild", "expected_hash": read.Hash})
+	recipe, _ = fake.Recipe("CHILI")
+	assert.Equal(t, "Mild", recipe.Notes)
+}
@@ -0,0 +1,158 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+func (s *Server) shoppingTools() []server.ServerTool {
+	buildShoppingListTool := mcp.NewTool("build_shopping_list",
+		mcp.WithDescription("Build a shopping list for several recipes from the Paprika 3 app. Ingredients needed by more than one recipe are merged into one item, converting between units, and the items are grouped by aisle. Optionally adds the items to a Paprika grocery list."),
+		mcp.WithArray("recipes", mcp.Description("The recipes to shop for"), mcp.Required(), mcp.Items(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"uid":      map[string]interface{}{"type": "string", "description": "The UID of the recipe"},
+				"servings": map[string]interface{}{"type": "number", "description": "The number of servings to shop for; defaults to the recipe's servings"},
+			},
+			"required": []string{"uid"},
+		})),
+		mcp.WithString("units", mcp.Description("The unit system for the amounts; defaults to the one most recipes use"), mcp.Enum(string(paprika.Metric), string(paprika.USCustomary))),
+		mcp.WithBoolean("add_to_grocery_list", mcp.Description("Add the items to a Paprika grocery list"), mcp.DefaultBool(false)),
+		mcp.WithString("list", mcp.Description("The name of the grocery list to add the items to; defaults to the default list"), mcp.DefaultString("")),
+	)
+
+	return []server.ServerTool{
+		{Tool: buildShoppingListTool, Handler: s.buildShoppingList},
+	}
+}
+
+// shoppingRecipe is an entry of the recipes argument of build_shopping_list
+type shoppingRecipe struct {
+	uid      string
+	servings float64
+}
+
+func shoppingRecipesArgument(args map[string]interface{}) ([]shoppingRecipe, error) {
+	items, ok := args["recipes"].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, errors.New("recipes are required")
+	}
+
+	recipes := make([]shoppingRecipe, 0, len(items))
+	for _, item := range items {
+		switch item := item.(type) {
+		case string:
+			// be lenient with clients that pass plain UIDs
+			recipes = append(recipes, shoppingRecipe{uid: item})
+		case map[string]interface{}:
+			uid := stringArgument(item, "uid")
+			if uid == "" {
+				return nil, errors.New("every recipe needs a uid")
+			}
+			recipes = append(recipes, shoppingRecipe{uid: uid, servings: floatArgument(item, "servings", 0)})
+		default:
+			return nil, errors.New("recipes must be an array of objects with a uid and optional servings")
+		}
+	}
+	return recipes, nil
+}
+
+func (s *Server) buildShoppingList(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start := time.Now()
+	args := req.Params.Arguments
+	wanted, err := shoppingRecipesArgument(args)
+	if err != nil {
+		return nil, err
+	}
+	var system paprika.UnitSystem
+	if units := stringArgument(args, "units"); units != "" {
+		if system, err = paprika.ParseUnitSystem(units); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	recipes := make([]*paprika.Recipe, 0, len(wanted))
+	for _, w := range wanted {
+		recipe, err := s.readRecipe(ctx, w.uid)
+		if err != nil {
+			return nil, err
+		}
+		if w.servings > 0 {
+			if recipe, err = recipe.ScaledToServings(w.servings); err != nil {
+				return nil, err
+			}
+		}
+		recipes = append(recipes, recipe)
+	}
+
+	items := paprika.NewShoppingList(system, recipes...)
+	markdown := shoppingListMarkdown(items)
+
+	if !boolArgument(args, "add_to_grocery_list", false) {
+		return mcp.NewToolResultText(markdown), nil
+	}
+
+	listUID, err := s.groceryListUID(ctx, stringArgument(args, "list"))
+	if err != nil {
+		return nil, err
+	}
+	groceries := make([]paprika.GroceryItem, 0, len(items))
+	for _, item := range items {
+		grocery := paprika.GroceryItem{
+			Name:       item.String(),
+			Ingredient: item.Name,
+			Aisle:      item.Aisle,
+			Recipe:     strings.Join(item.Recipes, ", "),
+			ListUID:    listUID,
+			OrderFlag:  len(groceries),
+		}
+		if len(recipes) == 1 {
+			grocery.RecipeUID = recipes[0].UID
+		}
+		groceries = append(groceries, grocery)
+	}
+	saved, err := s.paprika3.SaveGroceryItems(ctx, groceries...)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(start)
+	s.logger.Info("Added shopping list to grocery list", "recipes", len(recipes), "count", len(saved), "duration", duration)
+
+	return mcp.NewToolResultText(markdown + fmt.Sprintf("Added %d items to the grocery list\n", len(saved))), nil
+}
+
+// shoppingListMarkdown renders a shopping list as a markdown checklist grouped by aisle
+func shoppingListMarkdown(items []paprika.ShoppingItem) string {
+	var sb strings.Builder
+	sb.WriteString("# Shopping list\n")
+	if len(items) == 0 {
+		sb.WriteString("\n_Nothing to buy_\n\n")
+		return sb.String()
+	}
+
+	aisle := "\x00"
+	for _, item := range items {
+		if item.Aisle != aisle {
+			aisle = item.Aisle
+			if aisle == "" {
+				sb.WriteString("\n## Other\n")
+			} else {
+				sb.WriteString(fmt.Sprintf("\n## %s\n", aisle))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("- [ ] %s _(%s)_\n", item, strings.Join(item.Recipes, ", ")))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package mcpserver
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildShoppingList(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutGroceryList(paprika.GroceryList{UID: "MAIN", Name: "My Grocery List", IsDefault: true})
+	fake.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup", Servings: "2", Ingredients: "1 onion\n500 ml stock\nsalt"})
+	fake.PutRecipe(paprika.Recipe{UID: "STEW", Name: "Stew", Servings: "4", Ingredients: "2 onions, diced\n500 g beef\n1 tsp salt"})
+
+	texts := callTool(t, s, "build_shopping_list", map[string]interface{}{
+		"recipes": []interface{}{
+			map[string]interface{}{"uid": "SOUP", "servings": 4},
+			map[string]interface{}{"uid": "STEW"},
+		},
+	})
+	require.Len(t, texts, 1)
+	assert.Equal(t, "# Shopping list\n"+
+		"\n## Produce\n- [ ] 4 onions _(Soup, Stew)_\n"+
+		"\n## Meat & Seafood\n- [ ] 500 g beef _(Stew)_\n"+
+		"\n## Spices & Seasonings\n- [ ] 1 tsp salt _(Soup, Stew)_\n"+
+		"\n## Pantry\n- [ ] 1 l stock _(Soup)_\n\n", texts[0])
+	assert.Empty(t, fake.GroceryItems())
+
+	texts = callTool(t, s, "build_shopping_list", map[string]interface{}{
+		"recipes":             []interface{}{"SOUP", "STEW"},
+		"units":               "us",
+		"add_to_grocery_list": true,
+	})
+	assert.Contains(t, texts[0], "Added 4 items to the grocery list")
+
+	items := fake.GroceryItems()
+	require.Len(t, items, 4)
+	var onions paprika.GroceryItem
+	for _, item := range items {
+		if item.Ingredient == "onions" {
+			onions = item
+		}
+	}
+	assert.Equal(t, "3 onions", onions.Name)
+	assert.Equal(t, "Produce", onions.Aisle)
+	assert.Equal(t, "Soup, Stew", onions.Recipe)
+	assert.Equal(t, "MAIN", onions.ListUID)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "build_shopping_list",
+		"arguments": map[string]interface{}{"recipes": []interface{}{}},
+	})
+	require.NotNil(t, resp.Error)
+}
@@ -0,0 +1,129 @@
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stdioSession is the only client session of a server talking over stdio
+type stdioSession struct {
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+func (s *stdioSession) SessionID() string {
+	return "stdio"
+}
+
+func (s *stdioSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *stdioSession) Initialize() {
+	s.initialized.Store(true)
+}
+
+func (s *stdioSession) Initialized() bool {
+	return s.initialized.Load()
+}
+
+var _ server.ClientSession = (*stdioSession)(nil)
+
+// serveStdio serves a single client over stdin and stdout until the input is closed or ctx is cancelled.
+// It stands in for server.ServeStdio, which passes every message straight to mcp-go and so can't
+// support resource subscriptions.
+func (s *Server) serveStdio(ctx context.Context, stdin io.Reader, stdout io.Writer) error {
+	session := &stdioSession{notifications: make(chan mcp.JSONRPCNotification, 100)}
+	if err := s.server.RegisterSession(ctx, session); err != nil {
+		return fmt.Errorf("register session: %w", err)
+	}
+	defer func() {
+		s.server.UnregisterSession(session.SessionID())
+		s.sessions.Delete(session.SessionID())
+		s.subscriptions.drop(session.SessionID())
+	}()
+
+	ctx, cancel := context.WithCancel(s.server.WithContext(ctx, session))
+	defer cancel()
+
+	// Responses and notifications are written from different goroutines
+	var mu sync.Mutex
+	write := func(message mcp.JSONRPCMessage) error {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		_, err = fmt.Fprintf(stdout, "%s\n", data)
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case notification := <-session.notifications:
+				if err := write(notification); err != nil {
+					s.logger.Error("failed to write notification", "err", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(stdin)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case line := <-lines:
+			var message json.RawMessage
+			if err := json.Unmarshal([]byte(line), &message); err != nil {
+				if err := write(errorResponse(nil, mcp.PARSE_ERROR, "Parse error")); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// notifications don't get a response
+			if response := s.handleMessage(ctx, message); response != nil {
+				if err := write(response); err != nil {
+					return fmt.Errorf("failed to write response: %w", err)
+				}
+			}
+		}
+	}
+}
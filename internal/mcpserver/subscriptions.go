@@ -0,0 +1,127 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// subscriptions tracks which resources each client subscribed to
+type subscriptions struct {
+	mu sync.Mutex
+	// sessions maps a resource URI to the IDs of the sessions subscribed to it
+	sessions map[string]map[string]struct{}
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{sessions: make(map[string]map[string]struct{})}
+}
+
+// key normalizes a URI, since recipe UIDs are case insensitive
+func (s *subscriptions) key(uri string) string {
+	return strings.ToUpper(uri)
+}
+
+func (s *subscriptions) subscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.key(uri)
+	if s.sessions[key] == nil {
+		s.sessions[key] = make(map[string]struct{})
+	}
+	s.sessions[key][sessionID] = struct{}{}
+}
+
+func (s *subscriptions) unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.key(uri)
+	delete(s.sessions[key], sessionID)
+	if len(s.sessions[key]) == 0 {
+		delete(s.sessions, key)
+	}
+}
+
+// drop removes every subscription of a session that disconnected
+func (s *subscriptions) drop(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, sessions := range s.sessions {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(s.sessions, key)
+		}
+	}
+}
+
+// subscribers returns the IDs of the sessions subscribed to a resource
+func (s *subscriptions) subscribers(uri string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for id := range s.sessions[s.key(uri)] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// handleMessage handles a JSON-RPC message from a client. mcp-go doesn't route resource
+// subscriptions, so those are handled here and everything else is passed on to it.
+func (s *Server) handleMessage(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+	var request struct {
+		ID     mcp.RequestId `json:"id"`
+		Method string        `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	err := json.Unmarshal(message, &request)
+	if err != nil || (request.Method != methodResourcesSubscribe && request.Method != methodResourcesUnsubscribe) {
+		return s.server.HandleMessage(ctx, message)
+	}
+
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return errorResponse(request.ID, mcp.INTERNAL_ERROR, "subscriptions require a client session")
+	}
+	if request.Params.URI == "" {
+		return errorResponse(request.ID, mcp.INVALID_PARAMS, "uri is required")
+	}
+
+	if request.Method == methodResourcesSubscribe {
+		s.subscriptions.subscribe(session.SessionID(), request.Params.URI)
+		s.logger.Info("Subscribed to resource", "uri", request.Params.URI, "session", session.SessionID())
+	} else {
+		s.subscriptions.unsubscribe(session.SessionID(), request.Params.URI)
+		s.logger.Info("Unsubscribed from resource", "uri", request.Params.URI, "session", session.SessionID())
+	}
+
+	return mcp.JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      request.ID,
+		Result:  mcp.EmptyResult{},
+	}
+}
+
+func errorResponse(id mcp.RequestId, code int, message string) mcp.JSONRPCMessage {
+	response := mcp.JSONRPCError{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+	}
+	response.Error.Code = code
+	response.Error.Message = message
+	return response
+}
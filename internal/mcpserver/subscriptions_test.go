@@ -0,0 +1,147 @@
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribe sends a subscription request on behalf of a session
+func subscribe(t *testing.T, s *Server, session *testSession, method, uri string) rpcResponse {
+	t.Helper()
+
+	msg, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  map[string]interface{}{"uri": uri},
+	})
+	require.NoError(t, err)
+
+	ctx := s.server.WithContext(context.Background(), session)
+	raw, err := json.Marshal(s.handleMessage(ctx, msg))
+	require.NoError(t, err)
+
+	var resp rpcResponse
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	return resp
+}
+
+func TestResourceUpdatedNotifications(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup"})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Salad"})
+	s.refreshResources()
+
+	session := connect(t, s)
+	resp := subscribe(t, s, session, "resources/subscribe", "paprika://recipes/a")
+	require.Nil(t, resp.Error)
+	assert.JSONEq(t, `{}`, string(resp.Result))
+
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", Notes: "edited on the phone"})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Salad", Notes: "edited on the phone"})
+	s.refreshResources()
+
+	var updated []string
+	for {
+		select {
+		case n := <-session.notifications:
+			if n.Method == "notifications/resources/updated" {
+				updated = append(updated, n.Params.AdditionalFields["uri"].(string))
+			}
+			continue
+		default:
+		}
+		break
+	}
+	assert.Equal(t, []string{"paprika://recipes/A"}, updated)
+
+	resp = subscribe(t, s, session, "resources/unsubscribe", "paprika://recipes/A")
+	require.Nil(t, resp.Error)
+
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", Notes: "edited again"})
+	s.refreshResources()
+	assert.Equal(t, []string{"notifications/resources/list_changed"}, session.drain())
+}
+
+func TestSubscribeRequiresURI(t *testing.T) {
+	s, _ := newTestServer(t)
+	session := connect(t, s)
+
+	resp := subscribe(t, s, session, "resources/subscribe", "")
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, mcp.INVALID_PARAMS, resp.Error.Code)
+}
+
+func TestServeStdio(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup"})
+	s.refreshResources()
+
+	stdin, input := io.Pipe()
+	output, stdout := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.serveStdio(ctx, stdin, stdout)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	lines := make(chan map[string]interface{})
+	go func() {
+		scanner := bufio.NewScanner(output)
+		for scanner.Scan() {
+			var message map[string]interface{}
+			if json.Unmarshal(scanner.Bytes(), &message) == nil {
+				lines <- message
+			}
+		}
+	}()
+	send := func(message string) {
+		_, err := fmt.Fprintln(input, message)
+		require.NoError(t, err)
+	}
+	receive := func() map[string]interface{} {
+		select {
+		case message := <-lines:
+			return message
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a message")
+			return nil
+		}
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"test"},"capabilities":{}}}`)
+	message := receive()
+	assert.Contains(t, fmt.Sprint(message["result"]), "subscribe:true")
+
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"paprika://recipes/A"}}`)
+	message = receive()
+	assert.EqualValues(t, 2, message["id"])
+	assert.Nil(t, message["error"])
+
+	send(`not json`)
+	message = receive()
+	assert.NotNil(t, message["error"])
+
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup", Notes: "edited on the phone"})
+	s.refreshResources()
+
+	assert.Equal(t, "notifications/resources/list_changed", receive()["method"])
+	message = receive()
+	assert.Equal(t, "notifications/resources/updated", message["method"])
+	assert.Equal(t, map[string]interface{}{"uri": "paprika://recipes/A"}, message["params"])
+}
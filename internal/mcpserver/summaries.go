@@ -0,0 +1,196 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+const (
+	recipeSummariesURI = "paprika://recipe-summaries"
+	// maxSummaryPageSize caps the limit argument of list_recipe_summaries
+	maxSummaryPageSize = 500
+)
+
+func (s *Server) summaryTools() []server.ServerTool {
+	listRecipeSummariesTool := mcp.NewTool("list_recipe_summaries",
+		mcp.WithDescription("List the recipes in the Paprika 3 library as compact JSON summaries (uid, name, categories, rating, favourite flag, times and servings), one page at a time. Use this to browse the library without reading every recipe."),
+		mcp.WithString("sort", mcp.Description("How to order the recipes: by name, by rating (best first), by created date (newest first) or by modified date (most recently changed first)"), mcp.Enum("name", "rating", "created", "modified"), mcp.DefaultString("name")),
+		mcp.WithNumber("max_total_minutes", mcp.Description("Only list recipes that take at most this many minutes in total, e.g. 30 for quick recipes"), mcp.DefaultNumber(0)),
+		mcp.WithNumber("limit", mcp.Description("The maximum number of recipes per page"), mcp.DefaultNumber(100)),
+		mcp.WithString("cursor", mcp.Description("The next_cursor returned with the previous page; omit for the first page"), mcp.DefaultString("")),
+	)
+
+	return []server.ServerTool{
+		{Tool: listRecipeSummariesTool, Handler: s.listRecipeSummaries},
+	}
+}
+
+// recipeSummary is the compact form of a recipe returned by list_recipe_summaries
+type recipeSummary struct {
+	UID        string   `json:"uid"`
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"`
+	Rating     int      `json:"rating"`
+	Favorite   bool     `json:"favorite"`
+	PrepTime   string   `json:"prep_time,omitempty"`
+	CookTime   string   `json:"cook_time,omitempty"`
+	// TotalTime is computed from the prep and cook times if the recipe doesn't have one
+	TotalTime string `json:"total_time,omitempty"`
+	// PrepMinutes, CookMinutes and TotalMinutes are the parsed times, or zero if they are unknown
+	PrepMinutes  int    `json:"prep_minutes,omitempty"`
+	CookMinutes  int    `json:"cook_minutes,omitempty"`
+	TotalMinutes int    `json:"total_minutes,omitempty"`
+	Servings     string `json:"servings,omitempty"`
+	Created      string `json:"created,omitempty"`
+	// Modified is when the server last saw the recipe change; Paprika doesn't track this itself
+	Modified string `json:"modified,omitempty"`
+}
+
+type recipeSummaryPage struct {
+	Recipes []recipeSummary `json:"recipes"`
+	Total   int             `json:"total"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) listRecipeSummaries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.Params.Arguments
+	limit := intArgument(args, "limit", 100)
+	if limit <= 0 || limit > maxSummaryPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxSummaryPageSize)
+	}
+	offset, err := decodeCursor(stringArgument(args, "cursor"))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := s.awaitLibrary(ctx); err != nil {
+		return nil, err
+	}
+
+	recipes := s.library.all()
+	if maxMinutes := intArgument(args, "max_total_minutes", 0); maxMinutes > 0 {
+		recipes = slices.DeleteFunc(recipes, func(r *paprika.Recipe) bool {
+			total, ok := r.TotalDuration()
+			return !ok || total > time.Duration(maxMinutes)*time.Minute
+		})
+	}
+	switch sortBy := stringArgument(args, "sort"); sortBy {
+	case "", "name":
+		// the library is already sorted by name
+	case "rating":
+		sort.SliceStable(recipes, func(i, j int) bool { return recipes[i].Rating > recipes[j].Rating })
+	case "created":
+		sort.SliceStable(recipes, func(i, j int) bool { return recipes[i].Created > recipes[j].Created })
+	case "modified":
+		sort.SliceStable(recipes, func(i, j int) bool {
+			a, _ := s.library.modifiedAt(recipes[i].UID)
+			b, _ := s.library.modifiedAt(recipes[j].UID)
+			return a.After(b)
+		})
+	default:
+		return nil, fmt.Errorf("unknown sort %q, expected one of name, rating, created, modified", sortBy)
+	}
+
+	page := recipeSummaryPage{Recipes: []recipeSummary{}, Total: len(recipes)}
+	if offset > len(recipes) {
+		offset = len(recipes)
+	}
+	end := min(offset+limit, len(recipes))
+	for _, recipe := range recipes[offset:end] {
+		page.Recipes = append(page.Recipes, s.summarize(recipe))
+	}
+	if end < len(recipes) {
+		page.NextCursor = encodeCursor(end)
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Recipes %d-%d of %d", offset+1, end, len(recipes))
+	if len(page.Recipes) == 0 {
+		summary = fmt.Sprintf("No recipes on this page, the library has %d", len(recipes))
+	}
+	if page.NextCursor != "" {
+		summary += fmt.Sprintf("; pass cursor %q for the next page", page.NextCursor)
+	}
+
+	return mcp.NewToolResultResource(summary, mcp.TextResourceContents{
+		URI:      recipeSummariesURI,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}), nil
+}
+
+func (s *Server) summarize(recipe *paprika.Recipe) recipeSummary {
+	summary := recipeSummary{
+		UID:        recipe.UID,
+		Name:       recipe.Name,
+		Categories: s.library.categoryNames(recipe.Categories),
+		Rating:     recipe.Rating,
+		Favorite:   recipe.OnFavorites,
+		PrepTime:   recipe.PrepTime,
+		CookTime:   recipe.CookTime,
+		TotalTime:  recipe.TotalTime,
+		Servings:   recipe.Servings,
+		Created:    recipe.Created,
+	}
+	if prep, ok := recipe.PrepDuration(); ok {
+		summary.PrepMinutes = minutes(prep)
+	}
+	if cook, ok := recipe.CookDuration(); ok {
+		summary.CookMinutes = minutes(cook)
+	}
+	if total, ok := recipe.TotalDuration(); ok {
+		summary.TotalMinutes = minutes(total)
+		if summary.TotalTime == "" {
+			summary.TotalTime = paprika.FormatDuration(total)
+		}
+	}
+	if modified, ok := s.library.modifiedAt(recipe.UID); ok && !modified.IsZero() {
+		summary.Modified = modified.UTC().Format(paprika.DateLayout)
+	}
+	return summary
+}
+
+// minutes rounds a duration up to whole minutes
+func minutes(d time.Duration) int {
+	return int(math.Ceil(d.Minutes()))
+}
+
+// encodeCursor turns an offset into the library into an opaque cursor
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return offset, nil
+}
@@ -0,0 +1,139 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listSummaries(t *testing.T, s *Server, args map[string]interface{}) (string, recipeSummaryPage) {
+	t.Helper()
+
+	texts := callTool(t, s, "list_recipe_summaries", args)
+	require.Len(t, texts, 2)
+
+	var page recipeSummaryPage
+	require.NoError(t, json.Unmarshal([]byte(texts[1]), &page))
+	return texts[0], page
+}
+
+func TestListRecipeSummaries(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	fake.PutRecipe(paprika.Recipe{UID: "C", Name: "Chili", Categories: []string{"DINNER"}, Rating: 4, OnFavorites: true, CookTime: "1 hr", Servings: "6", Created: "2024-01-03 10:00:00"})
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Apple Pie", Rating: 5, Created: "2024-01-01 10:00:00"})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Bread", Created: "2024-01-02 10:00:00"})
+	fake.PutRecipe(paprika.Recipe{UID: "T", Name: "Trashed", InTrash: true})
+	s.refreshResources()
+
+	text, page := listSummaries(t, s, map[string]interface{}{})
+	assert.Equal(t, "Recipes 1-3 of 3", text)
+	assert.Equal(t, 3, page.Total)
+	assert.Empty(t, page.NextCursor)
+	require.Len(t, page.Recipes, 3)
+	assert.Equal(t, []string{"A", "B", "C"}, []string{page.Recipes[0].UID, page.Recipes[1].UID, page.Recipes[2].UID})
+	assert.Equal(t, recipeSummary{
+		UID:        "C",
+		Name:       "Chili",
+		Categories: []string{"Dinner"},
+		Rating:     4,
+		Favorite:   true,
+		CookTime:   "1 hr",
+		// the total time is computed from the prep and cook times
+		TotalTime:    "1 hr",
+		CookMinutes:  60,
+		TotalMinutes: 60,
+		Servings:     "6",
+		Created:      "2024-01-03 10:00:00",
+		// recipes are dated by their created date until they are seen changing
+		Modified: "2024-01-03 10:00:00",
+	}, page.Recipes[2])
+
+	_, page = listSummaries(t, s, map[string]interface{}{"sort": "rating"})
+	assert.Equal(t, []string{"A", "C", "B"}, []string{page.Recipes[0].UID, page.Recipes[1].UID, page.Recipes[2].UID})
+
+	_, page = listSummaries(t, s, map[string]interface{}{"sort": "created"})
+	assert.Equal(t, []string{"C", "B", "A"}, []string{page.Recipes[0].UID, page.Recipes[1].UID, page.Recipes[2].UID})
+}
+
+func TestListRecipeSummariesMaxTotalMinutes(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Omelette", PrepTime: "5 mins", CookTime: "10 mins"})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Bread", TotalTime: "PT3H"})
+	fake.PutRecipe(paprika.Recipe{UID: "C", Name: "Salad", TotalTime: "20-25 minutes"})
+	fake.PutRecipe(paprika.Recipe{UID: "D", Name: "Mystery"})
+	s.refreshResources()
+
+	_, page := listSummaries(t, s, map[string]interface{}{"max_total_minutes": 30})
+	require.Len(t, page.Recipes, 2)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, "A", page.Recipes[0].UID)
+	assert.Equal(t, 15, page.Recipes[0].TotalMinutes)
+	assert.Equal(t, "15 mins", page.Recipes[0].TotalTime)
+	assert.Equal(t, "C", page.Recipes[1].UID)
+	assert.Equal(t, 25, page.Recipes[1].TotalMinutes)
+}
+
+func TestListRecipeSummariesByModified(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Apple Pie", Created: "2024-01-01 10:00:00"})
+	fake.PutRecipe(paprika.Recipe{UID: "B", Name: "Bread", Created: "2024-01-02 10:00:00"})
+	s.refreshResources()
+
+	callTool(t, s, "update_paprika_recipe", map[string]interface{}{"uid": "A", "notes": "Use tart apples"})
+
+	_, page := listSummaries(t, s, map[string]interface{}{"sort": "modified"})
+	require.Len(t, page.Recipes, 2)
+	assert.Equal(t, []string{"A", "B"}, []string{page.Recipes[0].UID, page.Recipes[1].UID})
+	// updating a recipe keeps its created date
+	assert.Equal(t, "2024-01-01 10:00:00", page.Recipes[0].Created)
+	assert.Greater(t, page.Recipes[0].Modified, "2024-01-02 10:00:00")
+
+	updated, ok := fake.Recipe("A")
+	require.True(t, ok)
+	assert.Equal(t, "2024-01-01 10:00:00", updated.Created)
+}
+
+func TestListRecipeSummariesPagination(t *testing.T) {
+	s, fake := newTestServer(t)
+	for _, uid := range []string{"A", "B", "C", "D", "E"} {
+		fake.PutRecipe(paprika.Recipe{UID: uid, Name: "Recipe " + uid})
+	}
+	s.refreshResources()
+
+	var uids []string
+	cursor := ""
+	for pages := 0; pages < 5; pages++ {
+		_, page := listSummaries(t, s, map[string]interface{}{"limit": 2, "cursor": cursor})
+		for _, r := range page.Recipes {
+			uids = append(uids, r.UID)
+		}
+		cursor = page.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"A", "B", "C", "D", "E"}, uids)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "list_recipe_summaries",
+		"arguments": map[string]interface{}{"cursor": "not a cursor"},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "invalid cursor")
+}
+
+func TestListRecipeSummariesWaitsForFirstRefresh(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "A", Name: "Soup"})
+
+	go s.refreshResources()
+
+	// called without arguments, like the Makefile's debug-recipes target
+	resp := rpc(t, s, "tools/call", map[string]interface{}{"name": "list_recipe_summaries"})
+	require.Nil(t, resp.Error)
+	assert.Contains(t, string(resp.Result), `\"uid\":\"A\"`)
+}
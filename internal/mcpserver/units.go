@@ -0,0 +1,44 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+func (s *Server) unitTools() []server.ServerTool {
+	convertRecipeUnitsTool := mcp.NewTool("convert_recipe_units",
+		mcp.WithDescription("Show a recipe from the Paprika 3 app with its ingredients and oven temperatures converted to metric (grams, millilitres, °C) or US customary units (cups, ounces, °F). Common ingredients like flour, sugar and butter are converted between cups and grams. The recipe in Paprika is not changed."),
+		mcp.WithString("uid", mcp.Description("The UID of the recipe"), mcp.Required()),
+		mcp.WithString("system", mcp.Description("The unit system to convert to"), mcp.Enum(string(paprika.Metric), string(paprika.USCustomary)), mcp.Required()),
+	)
+
+	return []server.ServerTool{
+		{Tool: convertRecipeUnitsTool, Handler: s.convertRecipeUnits},
+	}
+}
+
+func (s *Server) convertRecipeUnits(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	uid := stringArgument(req.Params.Arguments, "uid")
+	if uid == "" {
+		return nil, errors.New("uid is required")
+	}
+	system, err := paprika.ParseUnitSystem(stringArgument(req.Params.Arguments, "system"))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	recipe, err := s.readRecipe(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := s.recipeCategories(ctx, recipe)
+	return mcp.NewToolResultText(recipe.ToMarkdown(paprika.WithCategories(categories), paprika.WithUnits(system))), nil
+}
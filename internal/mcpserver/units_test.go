@@ -0,0 +1,30 @@
+package mcpserver
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertRecipeUnits(t *testing.T) {
+	s, fake := newTestServer(t)
+	fake.PutRecipe(paprika.Recipe{UID: "CAKE", Name: "Cake", Ingredients: "2 cups flour\n1 cup milk\n3 eggs", Directions: "Bake at 350°F for 30 minutes"})
+
+	texts := callTool(t, s, "convert_recipe_units", map[string]interface{}{"uid": "CAKE", "system": "metric"})
+	require.Len(t, texts, 1)
+	assert.Contains(t, texts[0], "- 250 g flour\n- 245 g milk\n- 3 eggs\n")
+	assert.Contains(t, texts[0], "1. Bake at 180°C for 30 minutes\n")
+
+	recipe, ok := fake.Recipe("CAKE")
+	require.True(t, ok)
+	assert.Equal(t, "2 cups flour\n1 cup milk\n3 eggs", recipe.Ingredients)
+
+	resp := rpc(t, s, "tools/call", map[string]interface{}{
+		"name":      "convert_recipe_units",
+		"arguments": map[string]interface{}{"uid": "CAKE", "system": "imperial"},
+	})
+	require.NotNil(t, resp.Error)
+	assert.Contains(t, resp.Error.Message, "unknown unit system")
+}
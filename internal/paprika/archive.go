@@ -0,0 +1,301 @@
+package paprika
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveExtension is the file extension of the archives the Paprika apps export and import
+const ArchiveExtension = ".paprikarecipes"
+
+// ArchiveRecipe is a recipe in a .paprikarecipes archive. Unlike in the sync API, Categories
+// holds the names of the categories rather than their UIDs, and the photo is embedded.
+type ArchiveRecipe struct {
+	Recipe
+	// PhotoData is the recipe's photo, base64 encoded
+	PhotoData string `json:"photo_data,omitempty"`
+}
+
+// WriteArchive writes recipes to w as a .paprikarecipes archive: a zip file holding one
+// gzipped JSON file per recipe
+func WriteArchive(w io.Writer, recipes []ArchiveRecipe) error {
+	archive := zip.NewWriter(w)
+	names := make(map[string]int)
+	for _, recipe := range recipes {
+		data, err := gzipJSON(recipe)
+		if err != nil {
+			return err
+		}
+
+		name := archiveEntryName(recipe.Name, names)
+		// the entries are already gzipped, so they are stored rather than compressed again
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// archiveEntryName returns a file name for a recipe that is unique within the archive
+func archiveEntryName(recipeName string, used map[string]int) string {
+	base := safeFileName(recipeName)
+	used[strings.ToLower(base)]++
+	if n := used[strings.ToLower(base)]; n > 1 {
+		base = fmt.Sprintf("%s (%d)", base, n)
+	}
+	return base + ".paprikarecipe"
+}
+
+// safeFileName turns a recipe's name into a file name that is valid on every OS
+func safeFileName(name string) string {
+	base := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if base == "" {
+		return "Recipe"
+	}
+	return base
+}
+
+// ReadArchive reads the recipes of a .paprikarecipes archive. Files in the archive that aren't recipes are ignored.
+func ReadArchive(r io.ReaderAt, size int64) ([]ArchiveRecipe, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	var recipes []ArchiveRecipe
+	for _, f := range archive.File {
+		if path.Ext(f.Name) != ".paprikarecipe" {
+			continue
+		}
+
+		recipe, err := readArchiveEntry(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes, nil
+}
+
+func readArchiveEntry(f *zip.File) (ArchiveRecipe, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return ArchiveRecipe{}, err
+	}
+	defer rc.Close()
+
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return ArchiveRecipe{}, err
+	}
+	defer gz.Close()
+
+	var recipe ArchiveRecipe
+	if err := json.NewDecoder(gz).Decode(&recipe); err != nil {
+		return ArchiveRecipe{}, err
+	}
+	return recipe, nil
+}
+
+// WriteArchiveFile writes recipes to an archive file. The archive is written to a temporary
+// file first, so a failed export doesn't leave a truncated archive behind.
+func WriteArchiveFile(path string, recipes []ArchiveRecipe) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := WriteArchive(f, recipes); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// ReadArchiveFile reads the recipes of an archive file
+func ReadArchiveFile(path string) ([]ArchiveRecipe, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return ReadArchive(f, info.Size())
+}
+
+// DownloadPhoto downloads the photo of a recipe, or returns nil if it doesn't have one.
+// Photos are served from their own host, so they are requested without the API's credentials.
+func (c *Client) DownloadPhoto(ctx context.Context, recipe *Recipe) ([]byte, error) {
+	if recipe.PhotoURL == "" {
+		return nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, recipe.PhotoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.downloads.Do(req)
+	if err != nil {
+		c.logger.Error("failed to download photo", "uid", recipe.UID, "error", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Error("failed to download photo", "uid", recipe.UID, "status", resp.Status)
+		return nil, fmt.Errorf("failed to download photo of %s: %s", recipe.Name, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ExportOptions configures ExportRecipes
+type ExportOptions struct {
+	// UIDs are the recipes to export; defaults to every recipe that isn't in the trash
+	UIDs []string
+	// Photos embeds the recipes' photos
+	Photos bool
+}
+
+// ExportRecipes downloads recipes in the form they are stored in archives
+func (c *Client) ExportRecipes(ctx context.Context, opts ExportOptions) ([]ArchiveRecipe, error) {
+	categories, err := c.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	uids := opts.UIDs
+	exportAll := len(uids) == 0
+	if exportAll {
+		list, err := c.ListRecipes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Result {
+			uids = append(uids, item.UID)
+		}
+	}
+
+	recipes := make([]ArchiveRecipe, 0, len(uids))
+	for _, uid := range uids {
+		recipe, err := c.GetRecipe(ctx, uid)
+		if err != nil {
+			return nil, err
+		}
+		if exportAll && recipe.InTrash {
+			continue
+		}
+
+		archived := ArchiveRecipe{Recipe: *recipe}
+		archived.Categories = categories.Names(recipe.Categories)
+		if opts.Photos {
+			photo, err := c.DownloadPhoto(ctx, recipe)
+			if err != nil {
+				return nil, err
+			}
+			if photo != nil {
+				archived.PhotoData = base64.StdEncoding.EncodeToString(photo)
+			}
+		}
+		recipes = append(recipes, archived)
+	}
+
+	return recipes, nil
+}
+
+// ImportOptions configures ImportRecipes
+type ImportOptions struct {
+	// Overwrite replaces recipes that already exist in the account. Otherwise they are skipped.
+	Overwrite bool
+}
+
+// ImportResult describes what ImportRecipes did
+type ImportResult struct {
+	Saved []*Recipe
+	// Skipped are the names of recipes that already existed
+	Skipped []string
+	// PhotosSkipped counts the embedded photos that weren't imported; the sync API has no known way to upload them
+	PhotosSkipped int
+}
+
+// ImportRecipes saves archived recipes to the account, keeping their UIDs and created dates.
+// Categories are matched by name and created if they don't exist yet.
+func (c *Client) ImportRecipes(ctx context.Context, recipes []ArchiveRecipe, opts ImportOptions) (*ImportResult, error) {
+	list, err := c.ListRecipes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(list.Result))
+	for _, item := range list.Result {
+		existing[strings.ToUpper(item.UID)] = true
+	}
+
+	skip := func(uid string) bool {
+		return uid != "" && existing[strings.ToUpper(uid)] && !opts.Overwrite
+	}
+
+	// create the missing categories of all recipes at once, then map names to UIDs locally
+	var names []string
+	for _, archived := range recipes {
+		if !skip(archived.UID) {
+			names = append(names, archived.Categories...)
+		}
+	}
+	categories, err := c.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if categories, err = c.addCategories(ctx, categories, names); err != nil {
+		return nil, err
+	}
+
+	var result ImportResult
+	for _, archived := range recipes {
+		recipe := archived.Recipe
+		if skip(recipe.UID) {
+			result.Skipped = append(result.Skipped, recipe.Name)
+			continue
+		}
+
+		recipe.Categories, _ = categories.UIDs(recipe.Categories)
+		// the photo files belong to the account the recipe was exported from
+		recipe.Photo, recipe.PhotoHash, recipe.PhotoLarge, recipe.PhotoURL = "", "", "", ""
+		if archived.PhotoData != "" {
+			result.PhotosSkipped++
+		}
+
+		saved, err := c.SaveRecipe(ctx, recipe)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", recipe.Name, err)
+		}
+		result.Saved = append(result.Saved, saved)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,101 @@
+package paprika_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	recipes := []paprika.ArchiveRecipe{
+		{Recipe: paprika.Recipe{UID: "A", Name: "Soup", Ingredients: "1 onion", Categories: []string{"Dinner"}}, PhotoData: "cGhvdG8="},
+		{Recipe: paprika.Recipe{UID: "B", Name: "Soup"}},
+		{Recipe: paprika.Recipe{UID: "C", Name: "Salt/Pepper"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, paprika.WriteArchive(&buf, recipes))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"Soup.paprikarecipe", "Soup (2).paprikarecipe", "Salt_Pepper.paprikarecipe"}, names)
+
+	read, err := paprika.ReadArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, recipes, read)
+
+	_, err = paprika.ReadArchive(bytes.NewReader([]byte("not a zip")), 9)
+	assert.Error(t, err)
+}
+
+func TestExportAndImportRecipes(t *testing.T) {
+	photos := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// photo URLs are presigned, so they must not get the API's credentials
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.Write([]byte("photo"))
+	}))
+	defer photos.Close()
+
+	from := paprikatest.NewServer()
+	defer from.Close()
+	from.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	from.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup", Categories: []string{"DINNER"}, Created: "2020-01-02 03:04:05", PhotoURL: photos.URL + "/soup.jpg", Photo: "soup.jpg"})
+	from.PutRecipe(paprika.Recipe{UID: "OLD", Name: "Old", InTrash: true})
+
+	client, err := from.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	exported, err := client.ExportRecipes(ctx, paprika.ExportOptions{Photos: true})
+	require.NoError(t, err)
+	require.Len(t, exported, 1)
+	assert.Equal(t, []string{"Dinner"}, exported[0].Categories)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("photo")), exported[0].PhotoData)
+
+	to := paprikatest.NewServer()
+	defer to.Close()
+	to.PutRecipe(paprika.Recipe{UID: "EXISTING", Name: "Existing"})
+	target, err := to.NewClient()
+	require.NoError(t, err)
+
+	exported = append(exported,
+		paprika.ArchiveRecipe{Recipe: paprika.Recipe{UID: "EXISTING", Name: "Replacement"}},
+		paprika.ArchiveRecipe{Recipe: paprika.Recipe{UID: "STEW", Name: "Stew", Categories: []string{"dinner", "Stews"}}},
+	)
+	result, err := target.ImportRecipes(ctx, exported, paprika.ImportOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Saved, 2)
+	assert.Equal(t, []string{"Replacement"}, result.Skipped)
+	assert.Equal(t, 1, result.PhotosSkipped)
+	// the categories are listed and created once for all recipes
+	assert.Equal(t, 1, to.Requests("GET", "/api/v2/sync/categories"))
+	assert.Equal(t, 1, to.Requests("POST", "/api/v2/sync/categories"))
+	assert.Len(t, to.Categories(), 2)
+
+	soup, ok := to.Recipe("SOUP")
+	require.True(t, ok)
+	assert.Equal(t, "2020-01-02 03:04:05", soup.Created)
+	assert.Empty(t, soup.PhotoURL)
+	require.Len(t, soup.Categories, 1)
+	category, ok := to.Categories().Lookup(soup.Categories[0])
+	require.True(t, ok)
+	assert.Equal(t, "Dinner", category.Name)
+
+	_, err = target.ImportRecipes(ctx, exported[1:2], paprika.ImportOptions{Overwrite: true})
+	require.NoError(t, err)
+	existing, _ := to.Recipe("EXISTING")
+	assert.Equal(t, "Replacement", existing.Name)
+}
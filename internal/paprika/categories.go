@@ -0,0 +1,178 @@
+package paprika
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Category is a recipe category. Recipes reference categories by UID in Recipe.Categories.
+type Category struct {
+	UID       string `json:"uid"`
+	Name      string `json:"name"`
+	OrderFlag int    `json:"order_flag"`
+	ParentUID string `json:"parent_uid"`
+}
+
+// Categories is a list of categories with helpers to translate between names and UIDs
+type Categories []Category
+
+// Lookup returns the category with the given UID
+func (c Categories) Lookup(uid string) (Category, bool) {
+	for _, category := range c {
+		if strings.EqualFold(category.UID, uid) {
+			return category, true
+		}
+	}
+	return Category{}, false
+}
+
+// Find returns the category with the given name, ignoring case and surrounding whitespace
+func (c Categories) Find(name string) (Category, bool) {
+	name = strings.TrimSpace(name)
+	for _, category := range c {
+		if strings.EqualFold(category.Name, name) {
+			return category, true
+		}
+	}
+	return Category{}, false
+}
+
+// Names returns the names of the categories with the given UIDs. Unknown UIDs are skipped.
+func (c Categories) Names(uids []string) []string {
+	names := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if category, ok := c.Lookup(uid); ok {
+			names = append(names, category.Name)
+		}
+	}
+	return names
+}
+
+// UIDs returns the UIDs of the categories with the given names,
+// along with any names that don't match an existing category
+func (c Categories) UIDs(names []string) (uids []string, missing []string) {
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		if category, ok := c.Find(name); ok {
+			uids = append(uids, category.UID)
+			continue
+		}
+		missing = append(missing, name)
+	}
+	return uids, missing
+}
+
+// ListCategories retrieves all recipe categories
+func (c *Client) ListCategories(ctx context.Context) (Categories, error) {
+	var categories Categories
+	if err := c.get(ctx, "/api/v2/sync/categories/", "categories", &categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// SaveCategories creates or updates the given categories. Categories without a UID are created.
+func (c *Client) SaveCategories(ctx context.Context, categories ...Category) (Categories, error) {
+	saved := make(Categories, 0, len(categories))
+	for _, category := range categories {
+		if category.UID == "" {
+			category.UID = strings.ToUpper(uuid.New().String())
+		}
+		saved = append(saved, category)
+	}
+
+	if err := c.uploadJSON(ctx, "/api/v2/sync/categories/", "save categories", saved); err != nil {
+		return nil, err
+	}
+
+	defer c.notify(ctx)
+
+	return saved, nil
+}
+
+// CreateCategory creates a new top-level category with the given name
+func (c *Client) CreateCategory(ctx context.Context, name string) (*Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("category name is required")
+	}
+
+	saved, err := c.SaveCategories(ctx, Category{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved[0], nil
+}
+
+// RenameCategory changes the name of an existing category
+// TODO: reverse-engineer category deletion; like recipes, categories currently have to be deleted in-app
+func (c *Client) RenameCategory(ctx context.Context, uid, name string) (*Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("category name is required")
+	}
+
+	categories, err := c.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	category, ok := categories.Lookup(uid)
+	if !ok {
+		return nil, fmt.Errorf("category %s not found", uid)
+	}
+
+	category.Name = name
+	saved, err := c.SaveCategories(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved[0], nil
+}
+
+// ResolveCategories translates category names into UIDs, creating any categories that don't exist yet
+func (c *Client) ResolveCategories(ctx context.Context, names []string) ([]string, error) {
+	categories, err := c.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if categories, err = c.addCategories(ctx, categories, names); err != nil {
+		return nil, err
+	}
+	uids, _ := categories.UIDs(names)
+	return uids, nil
+}
+
+// addCategories creates the categories named in names that aren't in categories yet, and returns categories
+// with them added. Callers resolving the names of many recipes can list the categories once and map names locally.
+func (c *Client) addCategories(ctx context.Context, categories Categories, names []string) (Categories, error) {
+	_, missing := categories.UIDs(names)
+	if len(missing) == 0 {
+		return categories, nil
+	}
+
+	created := make(Categories, 0, len(missing))
+	for _, name := range missing {
+		// don't create the same category twice when a name is repeated
+		if _, ok := created.Find(name); ok {
+			continue
+		}
+		created = append(created, Category{Name: strings.TrimSpace(name)})
+	}
+
+	created, err := c.SaveCategories(ctx, created...)
+	if err != nil {
+		return nil, err
+	}
+	return append(slices.Clip(categories), created...), nil
+}
@@ -0,0 +1,53 @@
+package paprika_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategories(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	created, err := client.CreateCategory(ctx, " Dessert ")
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.UID)
+	assert.Equal(t, "Dessert", created.Name)
+
+	renamed, err := client.RenameCategory(ctx, created.UID, "Desserts")
+	require.NoError(t, err)
+	assert.Equal(t, created.UID, renamed.UID)
+
+	_, err = client.RenameCategory(ctx, "MISSING", "Nope")
+	assert.Error(t, err)
+
+	categories, err := client.ListCategories(ctx)
+	require.NoError(t, err)
+	assert.Len(t, categories, 2)
+	assert.Equal(t, []string{"Desserts", "Dinner"}, categories.Names([]string{created.UID, "DINNER", "UNKNOWN"}))
+
+	uids, err := client.ResolveCategories(ctx, []string{"dinner", "Breakfast", "breakfast", ""})
+	require.NoError(t, err)
+	require.Len(t, uids, 3)
+	assert.Equal(t, "DINNER", uids[0])
+	assert.Equal(t, uids[1], uids[2])
+	assert.Len(t, srv.Categories(), 3)
+}
+
+func TestRecipeToMarkdownCategories(t *testing.T) {
+	recipe := paprika.Recipe{Name: "Soup", Categories: []string{"A", "B"}}
+	categories := paprika.Categories{{UID: "A", Name: "Dinner"}, {UID: "B", Name: "Vegetarian"}}
+
+	assert.Contains(t, recipe.ToMarkdown(paprika.WithCategories(categories)), "- **Categories:** Dinner, Vegetarian\n")
+	assert.NotContains(t, recipe.ToMarkdown(), "Categories")
+}
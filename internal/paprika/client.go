@@ -13,18 +13,21 @@ import (
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/url"
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 // roundTripper is a wrapper around http.RoundTripper
-// that adds the specified headers to each request
+// that adds the specified headers and an authentication token to each request
 type roundTripper struct {
 	headers   map[string]string
+	tokens    *tokenSource
 	transport http.RoundTripper
 }
 
@@ -35,16 +38,69 @@ func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 			req.Header.Set(k, v)
 		}
 	}
+	if req.Header.Get("Authorization") == "" {
+		token, err := r.tokens.Token(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("failed to login: %w", err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
 	return r.transport.RoundTrip(req)
 }
 
+// tokenSource logs in to the Paprika API when a token is first needed and remembers it.
+// A failed login is retried the next time a token is needed.
+type tokenSource struct {
+	mu       sync.Mutex
+	token    string
+	client   http.Client
+	baseURL  string
+	username string
+	password string
+}
+
+func (t *tokenSource) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" {
+		return t.token, nil
+	}
+
+	token, err := login(ctx, t.client, t.baseURL, t.username, t.password)
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	return token, nil
+}
+
 func userAgent(version string) string {
 	return fmt.Sprintf("paprika-3-mcp/%s (golang; %s)", version, runtime.Version())
 }
 
-func NewClient(username, password, version string, logger *slog.Logger) (*Client, error) {
-	// Create the http client & login to retrieve an authentication token
-	t := &http.Transport{
+// DefaultBaseURL is the base URL of the Paprika cloud sync API
+const DefaultBaseURL = "https://paprikaapp.com"
+
+type NewClientOptions struct {
+	Username string
+	Password string
+	Version  string
+	// BaseURL overrides the Paprika API host, e.g. to point the client at a local stand-in server.
+	// Defaults to DefaultBaseURL.
+	BaseURL string
+	// Transport is the underlying http.RoundTripper used for all requests, e.g. a proxy or recording transport.
+	// Defaults to an http.Transport with conservative timeouts.
+	Transport http.RoundTripper
+	// DeferLogin skips logging in when the client is created. The client logs in
+	// on its first request instead, so it can be created while Paprika is unreachable.
+	DeferLogin bool
+	Logger     *slog.Logger
+}
+
+func defaultTransport() http.RoundTripper {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
 		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
 			d := &net.Dialer{
 				Timeout:   5 * time.Second,
@@ -55,42 +111,73 @@ func NewClient(username, password, version string, logger *slog.Logger) (*Client
 		ResponseHeaderTimeout: 10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
+}
+
+func NewClient(opts NewClientOptions) (*Client, error) {
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	t := opts.Transport
+	if t == nil {
+		t = defaultTransport()
+	}
+
+	// Create the http client & login to retrieve an authentication token
 	client := &http.Client{
 		Transport: t,
 		Timeout:   10 * time.Second,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	token, err := login(ctx, *client, username, password)
-	if err != nil {
-		return nil, fmt.Errorf("failed to login: %w", err)
+	tokens := &tokenSource{
+		client:   *client,
+		baseURL:  baseURL,
+		username: opts.Username,
+		password: opts.Password,
+	}
+	if !opts.DeferLogin {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := tokens.Token(ctx); err != nil {
+			return nil, fmt.Errorf("failed to login: %w", err)
+		}
 	}
 
 	client.Transport = &roundTripper{
 		transport: t,
+		tokens:    tokens,
 		headers: map[string]string{
-			"Accept":        "*/*",
-			"Authorization": fmt.Sprintf("Bearer %s", token),
-			"Connection":    "keep-alive",
-			"User-Agent":    userAgent(version),
+			"Accept":     "*/*",
+			"Connection": "keep-alive",
+			"User-Agent": userAgent(opts.Version),
 		},
 	}
 
-	l := logger
+	l := opts.Logger
 	if l == nil {
 		l = slog.Default()
 	}
 
 	return &Client{
-		client: client,
-		logger: l,
+		client:  client,
+		baseURL: baseURL,
+		// downloads are made without the roundTripper, as they don't go to the API
+		downloads: &http.Client{Transport: t, Timeout: 30 * time.Second},
+		logger:    l,
 	}, nil
 }
 
 type Client struct {
-	client *http.Client
-	logger *slog.Logger
+	client    *http.Client
+	baseURL   string
+	downloads *http.Client
+	logger    *slog.Logger
+}
+
+// url returns the absolute URL for the given API path
+func (c *Client) url(path string) string {
+	return c.baseURL + path
 }
 
 type loginResponse struct {
@@ -108,9 +195,9 @@ type errorResponse struct {
 
 // login authenticates with the Paprika API and returns an authentication token
 // The token is used for all subsequent requests to the API. As far as I can tell, this is a JWT with no expiration.
-func login(ctx context.Context, client http.Client, username, password string) (string, error) {
-	body := fmt.Sprintf("email=%s&password=%s", username, password)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://paprikaapp.com/api/v1/account/login", bytes.NewBufferString(body))
+func login(ctx context.Context, client http.Client, baseURL, username, password string) (string, error) {
+	body := url.Values{"email": {username}, "password": {password}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/api/v1/account/login", bytes.NewBufferString(body))
 	if err != nil {
 		return "", err
 	}
@@ -154,7 +241,7 @@ type RecipeList struct {
 // ListRecipes retrieves a list of recipes from the Paprika API - the response objects
 // only contain the UID and hash of each recipe, not the full recipe object
 func (c *Client) ListRecipes(ctx context.Context) (*RecipeList, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://paprikaapp.com/api/v2/sync/recipes", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url("/api/v2/sync/recipes"), nil)
 	if err != nil {
 		c.logger.Error("failed to create request", "error", err)
 		return nil, err
@@ -227,7 +314,37 @@ func (r *Recipe) ResourceDescription() string {
 	return fmt.Sprintf("A recipe for %s: %s", r.Name, r.Description)
 }
 
-func (r *Recipe) ToMarkdown() string {
+type markdownOptions struct {
+	categories Categories
+	units      UnitSystem
+}
+
+// MarkdownOption customizes how Recipe.ToMarkdown renders a recipe
+type MarkdownOption func(*markdownOptions)
+
+// WithCategories renders the names of the recipe's categories, which are otherwise opaque UIDs
+func WithCategories(categories Categories) MarkdownOption {
+	return func(o *markdownOptions) {
+		o.categories = categories
+	}
+}
+
+// WithUnits renders the ingredients and the temperatures in the directions in the given unit system
+func WithUnits(system UnitSystem) MarkdownOption {
+	return func(o *markdownOptions) {
+		o.units = system
+	}
+}
+
+func (r *Recipe) ToMarkdown(opts ...MarkdownOption) string {
+	var o markdownOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.units != "" {
+		r = r.Converted(o.units)
+	}
+
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("# %s\n\n", r.Name))
@@ -236,7 +353,9 @@ func (r *Recipe) ToMarkdown() string {
 		sb.WriteString(fmt.Sprintf("_%s_\n\n", r.Description))
 	}
 
-	if r.Servings != "" || r.PrepTime != "" || r.CookTime != "" || r.Difficulty != "" {
+	categories := o.categories.Names(r.Categories)
+
+	if r.Servings != "" || r.PrepTime != "" || r.CookTime != "" || r.Difficulty != "" || len(categories) > 0 {
 		sb.WriteString("## Details\n")
 		if r.Servings != "" {
 			sb.WriteString(fmt.Sprintf("- **Servings:** %s\n", r.Servings))
@@ -250,6 +369,9 @@ func (r *Recipe) ToMarkdown() string {
 		if r.Difficulty != "" {
 			sb.WriteString(fmt.Sprintf("- **Difficulty:** %s\n", r.Difficulty))
 		}
+		if len(categories) > 0 {
+			sb.WriteString(fmt.Sprintf("- **Categories:** %s\n", strings.Join(categories, ", ")))
+		}
 		sb.WriteString("\n")
 	}
 
@@ -292,9 +414,30 @@ func (r *Recipe) generateUUID() {
 	r.UID = strings.ToUpper(r.UID)
 }
 
+// DateLayout is the layout the sync API uses for timestamps, e.g. Recipe.Created or Meal.Date
+const DateLayout = "2006-01-02 15:04:05"
+
+// updateCreated stamps new recipes with the current time in UTC. Recipes that already have a
+// created date, e.g. existing recipes or imports, keep theirs so sorting by date added works in-app.
 func (r *Recipe) updateCreated() {
-	layout := "2006-01-02 15:04:05"
-	r.Created = time.Now().Format(layout)
+	if r.Created == "" {
+		r.Created = time.Now().UTC().Format(DateLayout)
+	}
+}
+
+// CreatedAt returns when the recipe was created, if it has a valid created date. Dates are read as UTC.
+func (r *Recipe) CreatedAt() (time.Time, bool) {
+	for _, layout := range []string{DateLayout, time.DateOnly} {
+		if t, err := time.Parse(layout, r.Created); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// SetCreated sets the created date, e.g. to keep the original date of an imported recipe
+func (r *Recipe) SetCreated(t time.Time) {
+	r.Created = t.UTC().Format(DateLayout)
 }
 
 func (r *Recipe) asMap() (map[string]interface{}, error) {
@@ -345,7 +488,12 @@ func (r *Recipe) updateHash() error {
 }
 
 func (r *Recipe) asGzip() ([]byte, error) {
-	jsonBytes, err := json.Marshal(r)
+	return gzipJSON(r)
+}
+
+// gzipJSON marshals v to JSON and gzips it, which is the format the sync API expects for uploads
+func gzipJSON(v interface{}) ([]byte, error) {
+	jsonBytes, err := json.Marshal(v)
 	if err != nil {
 		return nil, err
 	}
@@ -369,7 +517,7 @@ type GetRecipeResponse struct {
 }
 
 func (c *Client) GetRecipe(ctx context.Context, uid string) (*Recipe, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://paprikaapp.com/api/v2/sync/recipe/%s/", uid), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(fmt.Sprintf("/api/v2/sync/recipe/%s/", uid)), nil)
 	if err != nil {
 		c.logger.Error("failed to create request", "error", err)
 		return nil, err
@@ -403,17 +551,27 @@ func (c *Client) GetRecipe(ctx context.Context, uid string) (*Recipe, error) {
 	return &recipeResp.Result, nil
 }
 
-func (c *Client) DeleteRecipe(ctx context.Context, recipe Recipe) (*Recipe, error) {
+func (c *Client) DeleteRecipe(ctx context.Context, recipe Recipe, opts ...SaveOption) (*Recipe, error) {
 	// Set the recipe to be in the trash
 	// TODO: reverse-engineer full deletions; currently a user must go in-app to empty their trash and fully delete something
 	recipe.InTrash = true
-	return c.SaveRecipe(ctx, recipe)
+	return c.SaveRecipe(ctx, recipe, opts...)
 }
 
 // SaveRecipe saves a recipe to the Paprika API. If the recipe already exists, it will be updated.
 // If the recipe does not exist, it will be created.
-func (c *Client) SaveRecipe(ctx context.Context, recipe Recipe) (*Recipe, error) {
-	// set the created timestamp
+func (c *Client) SaveRecipe(ctx context.Context, recipe Recipe, opts ...SaveOption) (*Recipe, error) {
+	var o saveOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.expectedHash != "" {
+		if err := c.checkHash(ctx, o.list, recipe.UID, o.expectedHash); err != nil {
+			return nil, err
+		}
+	}
+
+	// set the created timestamp for new recipes
 	recipe.updateCreated()
 	// generate a new UUID if one doesn't exist
 	recipe.generateUUID()
@@ -428,66 +586,129 @@ func (c *Client) SaveRecipe(ctx context.Context, recipe Recipe) (*Recipe, error)
 		return nil, err
 	}
 
+	if err := c.upload(ctx, fmt.Sprintf("/api/v2/sync/recipe/%s/", recipe.UID), "create recipe", fileData); err != nil {
+		return nil, err
+	}
+
+	defer c.notify(ctx)
+
+	return &recipe, nil
+}
+
+// get performs a GET against the sync API and decodes the "result" field of the response into v
+func (c *Client) get(ctx context.Context, path, what string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(path), nil)
+	if err != nil {
+		c.logger.Error("failed to create request", "error", err)
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Error("failed to get "+what, "error", err)
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.logger.Error("failed to get "+what, "status", resp.Status)
+		return fmt.Errorf("failed to get %s: %s", what, resp.Status)
+	}
+
+	rawBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Error("failed to read response body", "error", err)
+		return err
+	}
+
+	if err := isErrorResponse(rawBytes); err != nil {
+		c.logger.Error("failed to get "+what, "error", err)
+		return err
+	}
+
+	result := struct {
+		Result interface{} `json:"result"`
+	}{Result: v}
+	if err := json.Unmarshal(rawBytes, &result); err != nil {
+		c.logger.Error("failed to unmarshal response", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// upload POSTs gzipped JSON to the sync API as the "data" file of a multipart form,
+// which is how the Paprika apps upload every kind of object
+func (c *Client) upload(ctx context.Context, path, what string, fileData []byte) error {
 	// Create a multipart form request
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
 	part, err := writer.CreateFormFile("data", "data")
 	if err != nil {
 		c.logger.Error("failed to create form file", "error", err)
-		return nil, err
+		return err
 	}
 
 	// Write the gzipped JSON data to the form file
 	if _, err := part.Write(fileData); err != nil {
 		c.logger.Error("failed to write gzipped JSON data", "error", err)
-		return nil, err
+		return err
 	}
 	if err := writer.Close(); err != nil {
 		c.logger.Error("failed to close multipart writer", "error", err)
-		return nil, err
+		return err
 	}
 
 	// Create the HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("https://paprikaapp.com/api/v2/sync/recipe/%s/", recipe.UID), &body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url(path), &body)
 	if err != nil {
 		c.logger.Error("failed to create request", "error", err)
-		return nil, err
+		return err
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.ContentLength = int64(body.Len())
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		c.logger.Error("failed to create recipe", "error", err)
-		return nil, err
+		c.logger.Error("failed to "+what, "error", err)
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		c.logger.Error("failed to create recipe", "status", resp.Status)
-		return nil, fmt.Errorf("failed to create recipe: %s", resp.Status)
+		c.logger.Error("failed to "+what, "status", resp.Status)
+		return fmt.Errorf("failed to %s: %s", what, resp.Status)
 	}
 
 	rawBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		c.logger.Error("failed to read response body", "error", err)
-		return nil, err
+		return err
 	}
 
 	if err := isErrorResponse(rawBytes); err != nil {
-		c.logger.Error("failed to create recipe", "error", err)
-		return nil, err
+		c.logger.Error("failed to "+what, "error", err)
+		return err
 	}
 
-	defer c.notify(ctx)
+	return nil
+}
 
-	return &recipe, nil
+// uploadJSON gzips v and uploads it with upload
+func (c *Client) uploadJSON(ctx context.Context, path, what string, v interface{}) error {
+	fileData, err := gzipJSON(v)
+	if err != nil {
+		return err
+	}
+
+	return c.upload(ctx, path, what, fileData)
 }
 
 // notify sends a POST to /v2/sync/notify, which tells all Paprika clients to sync.
 // We usually defer this call after a recipe is created/updated/deleted, since we don't care whether it suceeds or not.
 func (c *Client) notify(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://paprikaapp.com/api/v2/sync/notify", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url("/api/v2/sync/notify"), nil)
 	if err != nil {
 		c.logger.Error("failed to create request", "error", err)
 		return err
@@ -4,19 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestClient(t *testing.T) {
-	username := os.Getenv("PAPRIKA_USERNAME")
-	password := os.Getenv("PAPRIKA_PASSWORD")
-	client, err := paprika.NewClient(username, password, "dev", nil)
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+
+	client, err := srv.NewClient()
 	require.NoError(t, err)
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
@@ -70,12 +74,19 @@ func TestClient(t *testing.T) {
 	require.NoError(t, err)
 	t.Logf("Deleted recipe: %s", recipe.Name)
 
+	stored, ok := srv.Recipe(uid)
+	require.True(t, ok)
+	assert.True(t, stored.InTrash)
+	assert.Equal(t, 3, srv.Notifications())
+
 	recipes, err := client.ListRecipes(ctx)
 	require.NoError(t, err)
+	require.Len(t, recipes.Result, 1)
 
 	for _, recipe := range recipes.Result {
 		r, err := client.GetRecipe(ctx, recipe.UID)
 		require.NoError(t, err)
+		assert.Equal(t, r.Hash, recipe.Hash)
 
 		t.Logf("Recipe: %s - %s", r.Name, r.Created)
 		if _, err := json.Marshal(r); err != nil {
@@ -83,3 +94,151 @@ func TestClient(t *testing.T) {
 		}
 	}
 }
+
+func TestSaveRecipePreservesCreated(t *testing.T) {
+	// created dates are stamped in UTC whatever the local time zone is
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	before := time.Now().Add(-time.Second)
+	recipe, err := client.SaveRecipe(ctx, paprika.Recipe{Name: "Soup"})
+	require.NoError(t, err)
+	created, ok := recipe.CreatedAt()
+	require.True(t, ok)
+	assert.True(t, created.After(before), "new recipes are stamped with the current time")
+	assert.WithinDuration(t, time.Now(), created, time.Minute)
+
+	// updates and trashing keep the original date
+	recipe.Created = "2020-05-01 12:00:00"
+	recipe, err = client.SaveRecipe(ctx, *recipe)
+	require.NoError(t, err)
+	recipe.Notes = "edited"
+	recipe, err = client.SaveRecipe(ctx, *recipe)
+	require.NoError(t, err)
+	assert.Equal(t, "2020-05-01 12:00:00", recipe.Created)
+	recipe, err = client.DeleteRecipe(ctx, *recipe)
+	require.NoError(t, err)
+	assert.Equal(t, "2020-05-01 12:00:00", recipe.Created)
+
+	// imports can set their own date
+	imported := paprika.Recipe{Name: "Imported"}
+	imported.SetCreated(time.Date(2015, 3, 14, 14, 26, 53, 0, time.Local))
+	saved, err := client.SaveRecipe(ctx, imported)
+	require.NoError(t, err)
+	stored, ok := srv.Recipe(saved.UID)
+	require.True(t, ok)
+	assert.Equal(t, "2015-03-14 09:26:53", stored.Created)
+}
+
+func TestClientLoginFailure(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+
+	_, err := paprika.NewClient(paprika.NewClientOptions{
+		Username: paprikatest.Username,
+		Password: "wrong",
+		BaseURL:  srv.URL,
+	})
+	assert.Error(t, err)
+}
+
+func TestClientDeferLogin(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	// the login failure surfaces on the first request instead
+	client, err := paprika.NewClient(paprika.NewClientOptions{
+		Username:   paprikatest.Username,
+		Password:   "wrong",
+		BaseURL:    srv.URL,
+		DeferLogin: true,
+	})
+	require.NoError(t, err)
+	_, err = client.ListRecipes(ctx)
+	assert.ErrorContains(t, err, "failed to login")
+
+	// a failed login is retried on the next request
+	client, err = paprika.NewClient(paprika.NewClientOptions{
+		Username:   paprikatest.Username,
+		Password:   paprikatest.Password,
+		BaseURL:    srv.URL,
+		DeferLogin: true,
+	})
+	require.NoError(t, err)
+	srv.InjectFailure(paprikatest.Failure{Method: http.MethodPost, Path: "/api/v1/account/login", Status: http.StatusBadGateway})
+	_, err = client.ListRecipes(ctx)
+	assert.ErrorContains(t, err, "502")
+	_, err = client.ListRecipes(ctx)
+	assert.NoError(t, err)
+}
+
+func TestClientInjectedFailures(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	srv.InjectFailure(paprikatest.Failure{Method: http.MethodGet, Path: "/api/v2/sync/recipes", Status: http.StatusServiceUnavailable})
+	_, err = client.ListRecipes(ctx)
+	assert.ErrorContains(t, err, "503")
+
+	// the failure is only injected once
+	_, err = client.ListRecipes(ctx)
+	assert.NoError(t, err)
+
+	// Paprika sometimes reports errors in the body of a 200 response
+	srv.InjectFailure(paprikatest.Failure{Method: http.MethodPost, Path: "/api/v2/sync/recipe/", Status: http.StatusOK, ErrorCode: 7, ErrorMessage: "invalid recipe"})
+	_, err = client.SaveRecipe(ctx, paprika.Recipe{Name: "Broken"})
+	assert.ErrorContains(t, err, "invalid recipe")
+	assert.Empty(t, srv.Recipes())
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests = append(r.requests, req)
+	body := `{"result":[]}`
+	if strings.HasSuffix(req.URL.Path, "/account/login") {
+		body = `{"result":{"token":"test-token"}}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+func TestClientBaseURLAndTransport(t *testing.T) {
+	transport := &recordingTransport{}
+	client, err := paprika.NewClient(paprika.NewClientOptions{
+		Username:  "user@example.com",
+		Password:  "secret",
+		Version:   "dev",
+		BaseURL:   "http://paprika.local:8080/",
+		Transport: transport,
+	})
+	require.NoError(t, err)
+
+	_, err = client.ListRecipes(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, transport.requests, 2)
+	assert.Equal(t, "http://paprika.local:8080/api/v1/account/login", transport.requests[0].URL.String())
+	assert.Equal(t, "http://paprika.local:8080/api/v2/sync/recipes", transport.requests[1].URL.String())
+	assert.Equal(t, "Bearer test-token", transport.requests[1].Header.Get("Authorization"))
+}
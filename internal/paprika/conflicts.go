@@ -0,0 +1,73 @@
+package paprika
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ConflictError is returned by SaveRecipe when the recipe changed in Paprika since it was read
+type ConflictError struct {
+	UID string
+	// ExpectedHash is the hash the recipe had when it was read
+	ExpectedHash string
+	// ActualHash is the hash the recipe has now, or empty if it no longer exists
+	ActualHash string
+}
+
+func (e *ConflictError) Error() string {
+	if e.ActualHash == "" {
+		return fmt.Sprintf("recipe %s was deleted since it was read", e.UID)
+	}
+	return fmt.Sprintf("recipe %s was changed since it was read (expected hash %s, found %s)", e.UID, e.ExpectedHash, e.ActualHash)
+}
+
+type saveOptions struct {
+	expectedHash string
+	list         *RecipeList
+}
+
+// SaveOption customizes how SaveRecipe saves a recipe
+type SaveOption func(*saveOptions)
+
+// WithExpectedHash makes SaveRecipe fail with a *ConflictError unless the recipe in Paprika
+// still has the given hash, i.e. it wasn't edited elsewhere since it was read.
+// The check narrows the window for lost updates but can't close it, since the API has no conditional writes.
+// It downloads the whole recipe list to find the recipe's current hash, so callers saving many recipes
+// should pass a list they already have with WithRecipeList.
+func WithExpectedHash(hash string) SaveOption {
+	return func(o *saveOptions) {
+		o.expectedHash = hash
+	}
+}
+
+// WithRecipeList makes the WithExpectedHash check look the recipe up in a list the caller already
+// fetched with ListRecipes, instead of downloading the list again. Edits made since the list was
+// fetched go unnoticed.
+func WithRecipeList(list *RecipeList) SaveOption {
+	return func(o *saveOptions) {
+		o.list = list
+	}
+}
+
+// checkHash compares the hash of the recipe in list, or in Paprika if list is nil, with the expected hash
+func (c *Client) checkHash(ctx context.Context, list *RecipeList, uid, expected string) error {
+	if list == nil {
+		var err error
+		if list, err = c.ListRecipes(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range list.Result {
+		if !strings.EqualFold(entry.UID, uid) {
+			continue
+		}
+		if entry.Hash != expected {
+			return &ConflictError{UID: uid, ExpectedHash: expected, ActualHash: entry.Hash}
+		}
+		return nil
+	}
+
+	return &ConflictError{UID: uid, ExpectedHash: expected}
+}
@@ -0,0 +1,94 @@
+package paprika_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveRecipeWithExpectedHash(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup"})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	recipe, err := client.GetRecipe(ctx, "SOUP")
+	require.NoError(t, err)
+	read := recipe.Hash
+
+	// the recipe is edited elsewhere
+	srv.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Tomato Soup"})
+
+	recipe.Notes = "stale edit"
+	_, err = client.SaveRecipe(ctx, *recipe, paprika.WithExpectedHash(read))
+	var conflict *paprika.ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, "SOUP", conflict.UID)
+	assert.Equal(t, read, conflict.ExpectedHash)
+	assert.NotEmpty(t, conflict.ActualHash)
+	assert.NotEqual(t, read, conflict.ActualHash)
+
+	stored, _ := srv.Recipe("SOUP")
+	assert.Equal(t, "Tomato Soup", stored.Name)
+	assert.Empty(t, stored.Notes)
+
+	// saving on top of the current version succeeds
+	recipe, err = client.GetRecipe(ctx, "SOUP")
+	require.NoError(t, err)
+	recipe.Notes = "fresh edit"
+	saved, err := client.SaveRecipe(ctx, *recipe, paprika.WithExpectedHash(recipe.Hash))
+	require.NoError(t, err)
+	assert.Equal(t, "fresh edit", saved.Notes)
+}
+
+func TestSaveRecipeWithExpectedHashOfDeletedRecipe(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup"})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	recipe, err := client.GetRecipe(ctx, "SOUP")
+	require.NoError(t, err)
+	srv.RemoveRecipe("SOUP")
+
+	_, err = client.SaveRecipe(ctx, *recipe, paprika.WithExpectedHash(recipe.Hash))
+	assert.EqualError(t, err, "recipe SOUP was deleted since it was read")
+	_, ok := srv.Recipe("SOUP")
+	assert.False(t, ok)
+}
+
+func TestSaveRecipeWithRecipeList(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup"})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	list, err := client.ListRecipes(ctx)
+	require.NoError(t, err)
+	recipe, err := client.GetRecipe(ctx, "SOUP")
+	require.NoError(t, err)
+
+	// the check uses the list rather than downloading it again
+	recipe.Notes = "edited"
+	_, err = client.SaveRecipe(ctx, *recipe, paprika.WithExpectedHash(recipe.Hash), paprika.WithRecipeList(list))
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.Requests("GET", "/api/v2/sync/recipes"))
+
+	_, err = client.SaveRecipe(ctx, *recipe, paprika.WithExpectedHash("stale"), paprika.WithRecipeList(list))
+	var conflict *paprika.ConflictError
+	require.True(t, errors.As(err, &conflict))
+}
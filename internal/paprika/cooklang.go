@@ -0,0 +1,596 @@
+package paprika
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CooklangExtension is the file extension of Cooklang recipes
+const CooklangExtension = ".cook"
+
+var (
+	// blockComment matches Cooklang's [- block comments -]
+	blockComment = regexp.MustCompile(`(?s)\[-.*?-\]`)
+	// timerText finds durations in directions that can be marked up as timers, e.g. "20 minutes"
+	timerText = regexp.MustCompile(`(?i)\b(\d+(?:[.,]\d+)?(?:\s*-\s*\d+(?:[.,]\d+)?)?)\s*(minutes?|mins?|hours?|hrs?|seconds?|secs?)\b`)
+)
+
+// cookwarePrefix starts the line of a recipe's notes that lists the cookware of a Cooklang recipe
+const cookwarePrefix = "Cookware: "
+
+// ParseCooklang parses a recipe written in Cooklang (https://cooklang.org). Steps become the recipe's
+// directions and the ingredients marked up in them, like @flour{250%g}, its ingredients. The cookware is
+// listed in the notes. name is used if the recipe's metadata has no title, e.g. the name of its file.
+// The recipe's Categories holds the names of its tags rather than UIDs.
+func ParseCooklang(name, text string) (*Recipe, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	meta, body, err := cooklangFrontMatter(text)
+	if err != nil {
+		return nil, err
+	}
+	body = blockComment.ReplaceAllString(body, "")
+
+	p := cooklangParser{sections: []*cooklangSection{{}}}
+	var notes, step []string
+	for _, line := range strings.Split(body, "\n") {
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, ">>"):
+			key, value, _ := strings.Cut(strings.TrimPrefix(line, ">>"), ":")
+			meta[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		case strings.HasPrefix(line, ">"):
+			notes = append(notes, strings.TrimSpace(strings.TrimPrefix(line, ">")))
+		case strings.HasPrefix(line, "="):
+			p.addStep(step)
+			step = nil
+			p.sections = append(p.sections, &cooklangSection{name: strings.TrimSpace(strings.Trim(line, "="))})
+		case line == "":
+			p.addStep(step)
+			step = nil
+		default:
+			step = append(step, line)
+		}
+	}
+	p.addStep(step)
+
+	r := &Recipe{Name: name}
+	applyCooklangMetadata(r, meta)
+
+	var ingredients, directions []string
+	for _, section := range p.sections {
+		if section.name != "" {
+			if len(section.ingredients) > 0 {
+				ingredients = append(ingredients, section.name+":")
+			}
+			directions = append(directions, section.name+":")
+		}
+		for _, ingredient := range section.ingredients {
+			ingredients = append(ingredients, ingredient.String())
+		}
+		directions = append(directions, section.steps...)
+	}
+	r.Ingredients = strings.Join(ingredients, "\n")
+	r.Directions = strings.Join(directions, "\n")
+
+	if len(p.cookware) > 0 {
+		notes = append(notes, cookwarePrefix+strings.Join(p.cookware, ", "))
+	}
+	r.Notes = strings.Join(notes, "\n")
+
+	return r, nil
+}
+
+// cooklangFrontMatter splits off the YAML front matter of a recipe, if it has any
+func cooklangFrontMatter(text string) (map[string]interface{}, string, error) {
+	meta := make(map[string]interface{})
+	if !strings.HasPrefix(text, "---\n") {
+		return meta, text, nil
+	}
+
+	front, body, ok := strings.Cut(text[len("---\n"):], "\n---")
+	if !ok {
+		return meta, text, nil
+	}
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		return nil, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+	if meta == nil {
+		meta = make(map[string]interface{})
+	}
+	// lowercase the keys, so "Servings" and "servings" are the same
+	for key, value := range meta {
+		delete(meta, key)
+		meta[strings.ToLower(key)] = value
+	}
+	_, body, _ = strings.Cut(body, "\n")
+	return meta, body, nil
+}
+
+// metaString returns the first of the given metadata keys that is set, as text
+func metaString(meta map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch v := meta[key].(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		case int:
+			return strconv.Itoa(v)
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case []interface{}:
+			var parts []string
+			for _, item := range v {
+				parts = append(parts, fmt.Sprint(item))
+			}
+			return strings.Join(parts, ", ")
+		}
+	}
+	return ""
+}
+
+// applyCooklangMetadata copies the metadata keys the Cooklang docs recommend into the recipe
+func applyCooklangMetadata(r *Recipe, meta map[string]interface{}) {
+	if title := metaString(meta, "title"); title != "" {
+		r.Name = title
+	}
+	r.UID = metaString(meta, "uid")
+	r.Description = metaString(meta, "description", "introduction")
+	r.Servings = metaString(meta, "servings", "serves", "yield")
+	r.PrepTime = metaString(meta, "prep time", "prep_time", "prep")
+	r.CookTime = metaString(meta, "cook time", "cook_time", "cook")
+	r.TotalTime = metaString(meta, "time", "duration", "total time", "total_time", "time required")
+	r.Difficulty = metaString(meta, "difficulty")
+	r.ImageURL = metaString(meta, "image", "picture")
+
+	switch source := meta["source"].(type) {
+	case map[string]interface{}:
+		r.Source = metaString(source, "name")
+		r.SourceURL = metaString(source, "url")
+	default:
+		if s := metaString(meta, "source", "source.url", "url"); strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+			r.SourceURL = s
+		} else {
+			r.Source = s
+		}
+	}
+	if r.Source == "" {
+		r.Source = metaString(meta, "author", "source.name")
+	}
+
+	for _, key := range []string{"tags", "categories"} {
+		switch tags := meta[key].(type) {
+		case []interface{}:
+			for _, tag := range tags {
+				r.Categories = append(r.Categories, strings.TrimSpace(fmt.Sprint(tag)))
+			}
+		case string:
+			for _, tag := range strings.Split(tags, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					r.Categories = append(r.Categories, tag)
+				}
+			}
+		}
+	}
+}
+
+type cooklangSection struct {
+	name        string
+	steps       []string
+	ingredients []Ingredient
+}
+
+type cooklangParser struct {
+	sections []*cooklangSection
+	cookware []string
+}
+
+// addStep parses the lines of a step and adds it to the current section
+func (p *cooklangParser) addStep(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	section := p.sections[len(p.sections)-1]
+	s := strings.Join(lines, " ")
+
+	var text strings.Builder
+	for i := 0; i < len(s); {
+		token, ok := parseCooklangToken(s[i:])
+		if !ok {
+			text.WriteByte(s[i])
+			i++
+			continue
+		}
+		i += token.length
+
+		switch token.kind {
+		case '@':
+			text.WriteString(token.name)
+			section.addIngredient(token.ingredient())
+		case '#':
+			text.WriteString(token.name)
+			if !containsFold(p.cookware, token.name) {
+				p.cookware = append(p.cookware, token.name)
+			}
+		case '~':
+			qty, unit, _ := strings.Cut(token.amount, "%")
+			text.WriteString(strings.TrimSpace(strings.TrimSpace(qty) + " " + strings.TrimSpace(unit)))
+		}
+	}
+	section.steps = append(section.steps, strings.TrimSpace(text.String()))
+}
+
+// addIngredient adds an ingredient to the section, adding up the amounts of ingredients used in several steps
+func (s *cooklangSection) addIngredient(ingredient Ingredient) {
+	for n, existing := range s.ingredients {
+		if !strings.EqualFold(existing.Name, ingredient.Name) || existing.Unit != ingredient.Unit || (existing.Quantity == nil) != (ingredient.Quantity == nil) {
+			continue
+		}
+		if existing.Quantity != nil {
+			total := *existing.Quantity
+			q := &total
+			addQuantity(&q, *ingredient.Quantity)
+			if !q.IsRange() {
+				q.Max = 0
+			}
+			s.ingredients[n].Quantity = q
+		}
+		if existing.Note == "" {
+			s.ingredients[n].Note = ingredient.Note
+		}
+		return
+	}
+	s.ingredients = append(s.ingredients, ingredient)
+}
+
+// cooklangToken is an @ingredient, #cookware or ~timer in a step
+type cooklangToken struct {
+	kind     byte
+	name     string
+	amount   string
+	note     string
+	optional bool
+	length   int
+}
+
+// parseCooklangToken parses the token at the start of s. Names of more than one word
+// must be followed by braces, e.g. @olive oil{}.
+func parseCooklangToken(s string) (cooklangToken, bool) {
+	t := cooklangToken{kind: s[0]}
+	if !strings.ContainsRune("@#~", rune(t.kind)) {
+		return t, false
+	}
+
+	i := 1
+	for t.kind == '@' && i < len(s) && strings.ContainsRune("?&-+", rune(s[i])) {
+		t.optional = t.optional || s[i] == '?'
+		i++
+	}
+	rest := s[i:]
+
+	if open := strings.IndexByte(rest, '{'); open >= 0 && !strings.ContainsAny(rest[:open], "@#~}\n.,;:!?()[]") {
+		if end := strings.IndexByte(rest[open:], '}'); end >= 0 && (open == 0 || startsWithLetter(rest)) {
+			t.name = strings.TrimSpace(rest[:open])
+			t.amount = rest[open+1 : open+end]
+			i += open + end + 1
+			return t.withNote(s, i)
+		}
+	}
+
+	// timers need braces, other tokens can be a single word without them
+	if t.kind == '~' || !startsWithLetter(rest) {
+		return t, false
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+	})
+	if end < 0 {
+		end = len(rest)
+	}
+	t.name = strings.TrimRight(rest[:end], "-")
+	return t.withNote(s, i+len(t.name))
+}
+
+// withNote reads the (note) that can follow an ingredient
+func (t cooklangToken) withNote(s string, i int) (cooklangToken, bool) {
+	if t.kind == '@' && strings.HasPrefix(s[i:], "(") {
+		if end := strings.IndexByte(s[i:], ')'); end >= 0 {
+			t.note = strings.TrimSpace(s[i+1 : i+end])
+			i += end + 1
+		}
+	}
+	t.length = i
+	return t, t.name != "" || t.kind == '~'
+}
+
+func startsWithLetter(s string) bool {
+	for _, r := range s {
+		return unicode.IsLetter(r)
+	}
+	return false
+}
+
+// ingredient converts an @ingredient token to an Ingredient. Amounts that aren't numbers, like {some},
+// are kept as a note.
+func (t cooklangToken) ingredient() Ingredient {
+	ingredient := Ingredient{Name: t.name, Note: t.note, Optional: t.optional}
+
+	qty, unit, _ := strings.Cut(t.amount, "%")
+	qty = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(qty), "="), "*")
+	unit = strings.TrimSpace(unit)
+	if qty == "" {
+		return ingredient
+	}
+
+	q, rest := parseQuantity(normalizeFractions(qty))
+	if q == nil || strings.TrimSpace(rest) != "" {
+		ingredient.Note = strings.TrimSpace(strings.Join([]string{strings.TrimSpace(qty + " " + unit), ingredient.Note}, ", "))
+		ingredient.Note = strings.Trim(ingredient.Note, ", ")
+		return ingredient
+	}
+
+	ingredient.Quantity = q
+	ingredient.Unit = unit
+	if canonical, ok := unitAliases[strings.ToLower(strings.TrimSuffix(unit, "."))]; ok {
+		ingredient.Unit = canonical
+	}
+	return ingredient
+}
+
+// cooklangFrontMatterFields are the metadata written by ToCooklang, in the order they are written
+type cooklangFrontMatterFields struct {
+	Title       string      `yaml:"title"`
+	UID         string      `yaml:"uid,omitempty"`
+	Description string      `yaml:"description,omitempty"`
+	Tags        []string    `yaml:"tags,omitempty"`
+	Servings    string      `yaml:"servings,omitempty"`
+	PrepTime    string      `yaml:"prep time,omitempty"`
+	CookTime    string      `yaml:"cook time,omitempty"`
+	TotalTime   string      `yaml:"time,omitempty"`
+	Difficulty  string      `yaml:"difficulty,omitempty"`
+	Source      interface{} `yaml:"source,omitempty"`
+	Image       string      `yaml:"image,omitempty"`
+}
+
+// ToCooklang renders the recipe in Cooklang. Ingredients are marked up where the directions mention them
+// by name, and ingredients that aren't mentioned are listed in a first step. categories are used to name
+// the recipe's categories, which become its tags.
+func (r *Recipe) ToCooklang(categories Categories) string {
+	front := cooklangFrontMatterFields{
+		Title:       r.Name,
+		UID:         r.UID,
+		Description: r.Description,
+		Tags:        categories.Names(r.Categories),
+		Servings:    r.Servings,
+		PrepTime:    r.PrepTime,
+		CookTime:    r.CookTime,
+		TotalTime:   r.TotalTime,
+		Difficulty:  r.Difficulty,
+		Image:       r.ImageURL,
+	}
+	switch {
+	case r.Source != "" && r.SourceURL != "":
+		front.Source = map[string]string{"name": r.Source, "url": r.SourceURL}
+	case r.SourceURL != "":
+		front.Source = r.SourceURL
+	case r.Source != "":
+		front.Source = r.Source
+	}
+	// marshalling a struct of strings can't fail
+	data, _ := yaml.Marshal(front)
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.Write(data)
+	sb.WriteString("---\n")
+
+	var cookware []string
+	var notes []string
+	for _, line := range strings.Split(r.Notes, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, cookwarePrefix):
+			for _, item := range strings.Split(strings.TrimPrefix(line, cookwarePrefix), ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					cookware = append(cookware, item)
+				}
+			}
+		case line != "":
+			notes = append(notes, "> "+line)
+		}
+	}
+	if len(notes) > 0 {
+		sb.WriteString("\n" + strings.Join(notes, "\n") + "\n")
+	}
+
+	var ingredients []Ingredient
+	for _, ingredient := range r.ParsedIngredients() {
+		if !ingredient.Header {
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+	mentioned := make([]bool, len(ingredients))
+	usedCookware := make([]bool, len(cookware))
+
+	var steps []string
+	for _, line := range strings.Split(r.Directions, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case isHeader(line):
+			steps = append(steps, "== "+strings.TrimSuffix(line, ":")+" ==")
+		default:
+			steps = append(steps, markupStep(line, ingredients, mentioned, cookware, usedCookware))
+		}
+	}
+
+	var unmentioned []string
+	for n, ingredient := range ingredients {
+		if !mentioned[n] && ingredient.Name != "" {
+			unmentioned = append(unmentioned, cooklangIngredient(ingredient, ingredient.Name))
+		}
+	}
+	if len(unmentioned) > 0 {
+		steps = append([]string{"Ingredients: " + strings.Join(unmentioned, ", ")}, steps...)
+	}
+
+	for _, step := range steps {
+		sb.WriteString("\n" + step + "\n")
+	}
+	return sb.String()
+}
+
+// span is a part of a direction to replace with a token
+type span struct {
+	start, end int
+	token      string
+}
+
+// markupStep marks up the first mention of each ingredient and piece of cookware in a direction,
+// and durations as timers
+func markupStep(line string, ingredients []Ingredient, mentioned []bool, cookware []string, usedCookware []bool) string {
+	var spans []span
+	overlaps := func(start, end int) bool {
+		for _, s := range spans {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+		return false
+	}
+	// longer names first, so "olive oil" is found before "oil"
+	order := make([]int, len(ingredients))
+	for n := range order {
+		order[n] = n
+	}
+	sort.SliceStable(order, func(i, j int) bool { return len(ingredients[order[i]].Name) > len(ingredients[order[j]].Name) })
+
+	for _, n := range order {
+		if mentioned[n] || ingredients[n].Name == "" {
+			continue
+		}
+		if start, end, ok := findWord(line, ingredients[n].Name, overlaps); ok {
+			spans = append(spans, span{start, end, cooklangIngredient(ingredients[n], line[start:end])})
+			mentioned[n] = true
+		}
+	}
+	for n, item := range cookware {
+		if usedCookware[n] {
+			continue
+		}
+		if start, end, ok := findWord(line, item, overlaps); ok {
+			spans = append(spans, span{start, end, "#" + cooklangName(line[start:end])})
+			usedCookware[n] = true
+		}
+	}
+	for _, m := range timerText.FindAllStringSubmatchIndex(line, -1) {
+		if !overlaps(m[0], m[1]) {
+			spans = append(spans, span{m[0], m[1], fmt.Sprintf("~{%s%%%s}", line[m[2]:m[3]], line[m[4]:m[5]])})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var sb strings.Builder
+	last := 0
+	for _, s := range spans {
+		sb.WriteString(line[last:s.start])
+		sb.WriteString(s.token)
+		last = s.end
+	}
+	sb.WriteString(line[last:])
+	return sb.String()
+}
+
+// findWord finds the first whole-word, case-insensitive occurrence of word in s that isn't taken
+func findWord(s, word string, taken func(start, end int) bool) (int, int, bool) {
+	pattern, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+	if err != nil {
+		return 0, 0, false
+	}
+	for _, m := range pattern.FindAllStringIndex(s, -1) {
+		if !taken(m[0], m[1]) {
+			return m[0], m[1], true
+		}
+	}
+	return 0, 0, false
+}
+
+// cooklangName adds the braces a name of more than one word needs
+func cooklangName(name string) string {
+	if strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+	}) >= 0 {
+		return name + "{}"
+	}
+	return name
+}
+
+// cooklangIngredient renders an ingredient as a token, e.g. @flour{250%g}(sifted)
+func cooklangIngredient(ingredient Ingredient, name string) string {
+	token := "@"
+	if ingredient.Optional {
+		token += "?"
+	}
+
+	switch {
+	case ingredient.Quantity != nil:
+		token += name + "{" + cooklangQuantity(*ingredient.Quantity)
+		if ingredient.Unit != "" {
+			token += "%" + unitName(ingredient.Unit, ingredient.Quantity.Value > 1 || ingredient.Quantity.IsRange())
+		}
+		token += "}"
+	default:
+		token += cooklangName(name)
+	}
+
+	if note := strings.NewReplacer("(", "", ")", "").Replace(ingredient.Note); note != "" {
+		token += "(" + note + ")"
+	}
+	return token
+}
+
+// cooklangQuantity renders a quantity in a form Cooklang understands: simple fractions like 1/2, or decimals
+func cooklangQuantity(q Quantity) string {
+	return q.format(func(v float64) string {
+		if s := formatFraction(v); !strings.Contains(s, " ") {
+			return s
+		}
+		return formatDecimal(v)
+	})
+}
+
+// ReadCooklangDir parses the Cooklang recipes in a directory and its subdirectories. Recipes without
+// a title are named after their file.
+func ReadCooklangDir(dir string) ([]*Recipe, error) {
+	var recipes []*Recipe
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != CooklangExtension {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		recipe, err := ParseCooklang(strings.TrimSuffix(d.Name(), CooklangExtension), string(data))
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		recipes = append(recipes, recipe)
+		return nil
+	})
+	return recipes, err
+}
@@ -0,0 +1,128 @@
+package paprika_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pancakes = `---
+title: Pancakes
+tags: [Breakfast, Quick]
+servings: 4
+source:
+  name: Grandma
+  url: https://example.com/pancakes
+prep time: 10 minutes
+---
+
+> Best eaten warm.
+
+-- the batter can rest overnight
+Crack the @eggs{3} into a #large bowl{} and whisk in @flour{125%grams}(sifted)
+and @milk{250%ml}.
+
+[- older versions used less milk -]
+Add a pinch of @salt and @?vanilla extract{1/2%tsp}.
+
+== Cooking ==
+
+Heat @butter{1%tbsp} in a #frying pan{} for ~{2%minutes}. Cook each pancake for ~{1-2%minutes}.
+Add more @butter{1%tbsp} as needed.
+`
+
+func TestParseCooklang(t *testing.T) {
+	recipe, err := paprika.ParseCooklang("pancakes", pancakes)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Pancakes", recipe.Name)
+	assert.Equal(t, []string{"Breakfast", "Quick"}, recipe.Categories)
+	assert.Equal(t, "4", recipe.Servings)
+	assert.Equal(t, "Grandma", recipe.Source)
+	assert.Equal(t, "https://example.com/pancakes", recipe.SourceURL)
+	assert.Equal(t, "10 minutes", recipe.PrepTime)
+	assert.Equal(t, "3 eggs\n125 g flour, sifted\n250 ml milk\nsalt\n1/2 tsp vanilla extract (optional)\nCooking:\n2 tbsp butter", recipe.Ingredients)
+	assert.Equal(t, "Crack the eggs into a large bowl and whisk in flour and milk.\n"+
+		"Add a pinch of salt and vanilla extract.\n"+
+		"Cooking:\n"+
+		"Heat butter in a frying pan for 2 minutes. Cook each pancake for 1-2 minutes. Add more butter as needed.", recipe.Directions)
+	assert.Equal(t, "Best eaten warm.\nCookware: large bowl, frying pan", recipe.Notes)
+}
+
+func TestParseCooklangMetadataLines(t *testing.T) {
+	recipe, err := paprika.ParseCooklang("toast", ">> servings: 2\n>> tags: breakfast, bread\n>> source: https://example.com\n\nToast the @bread{2%slices}.\n")
+	require.NoError(t, err)
+
+	assert.Equal(t, "toast", recipe.Name)
+	assert.Equal(t, "2", recipe.Servings)
+	assert.Equal(t, []string{"breakfast", "bread"}, recipe.Categories)
+	assert.Equal(t, "https://example.com", recipe.SourceURL)
+	assert.Equal(t, "2 slices bread", recipe.Ingredients)
+	assert.Equal(t, "Toast the bread.", recipe.Directions)
+
+	_, err = paprika.ParseCooklang("broken", "---\ntitle: [\n---\n")
+	assert.Error(t, err)
+}
+
+func TestRecipeToCooklang(t *testing.T) {
+	recipe := paprika.Recipe{
+		UID:         "SOUP",
+		Name:        "Soup",
+		Ingredients: "2 tbsp olive oil\n1 onion, chopped\n1 1/2 cups stock\nsalt",
+		Directions:  "Heat the olive oil in a pot.\nFry the onion for 5 minutes.\nTo finish:\nAdd the stock.",
+		Notes:       "Freezes well.\nCookware: pot",
+		Servings:    "2",
+		Source:      "Grandma",
+		Categories:  []string{"DINNER"},
+	}
+	text := recipe.ToCooklang(paprika.Categories{{UID: "DINNER", Name: "Dinner"}})
+
+	assert.Equal(t, `---
+title: Soup
+uid: SOUP
+tags:
+    - Dinner
+servings: "2"
+source: Grandma
+---
+
+> Freezes well.
+
+Ingredients: @salt
+
+Heat the @olive oil{2%tbsp} in a #pot.
+
+Fry the @onion{1}(chopped) for ~{5%minutes}.
+
+== To finish ==
+
+Add the @stock{1.5%cups}.
+`, text)
+
+	// the Cooklang reads back into the same recipe, apart from the step listing unmentioned ingredients
+	back, err := paprika.ParseCooklang("", text)
+	require.NoError(t, err)
+	assert.Equal(t, "SOUP", back.UID)
+	assert.Equal(t, []string{"Dinner"}, back.Categories)
+	assert.Equal(t, "salt\n2 tbsp olive oil\n1 onion, chopped\nTo finish:\n1 1/2 cups stock", back.Ingredients)
+	assert.Equal(t, "Ingredients: salt\nHeat the olive oil in a pot.\nFry the onion for 5 minutes.\nTo finish:\nAdd the stock.", back.Directions)
+	assert.Equal(t, recipe.Notes, back.Notes)
+}
+
+func TestReadCooklangDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "breakfast"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "breakfast", "Pancakes.cook"), []byte(pancakes), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Toast.cook"), []byte("Toast the @bread."), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Recipes"), 0o644))
+
+	recipes, err := paprika.ReadCooklangDir(dir)
+	require.NoError(t, err)
+	require.Len(t, recipes, 2)
+	assert.Equal(t, "Toast", recipes[0].Name)
+	assert.Equal(t, "Pancakes", recipes[1].Name)
+}
@@ -0,0 +1,152 @@
+package paprika
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// isoDuration matches ISO 8601 durations like "PT1H30M", as used by schema.org recipes
+	isoDuration = regexp.MustCompile(`(?i)^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+	// clockDuration matches durations like "1:30"
+	clockDuration = regexp.MustCompile(`^(\d+):(\d{2})$`)
+	durationPart  = regexp.MustCompile(`(?i)(` + number + `)(?:\s*[-–—]\s*|\s+to\s+)?(` + number + `)?\s*(days?|d|hours?|hrs?|h|minutes?|mins?|m|seconds?|secs?|s)\b`)
+	// bareDuration matches a single number or range without a unit, like "30" or "10-15"
+	bareDuration = regexp.MustCompile(`^(` + number + `)(?:(?:\s*[-–—]\s*|\s+to\s+)(` + number + `))?$`)
+	// rangeSeparator matches the text between the two ends of a range like "5 mins to 10 mins"
+	rangeSeparator = regexp.MustCompile(`(?i)^\s*(?:[-–—]|to)\s*$`)
+	// letterDigit separates run-together parts like "1h30m"
+	letterDigit = regexp.MustCompile(`([a-zA-Z])(\d)`)
+)
+
+// durationUnits are the units of durationPart, by their first letter
+var durationUnits = map[byte]time.Duration{'d': 24 * time.Hour, 'h': time.Hour, 'm': time.Minute, 's': time.Second}
+
+// ParseDuration parses prep, cook and total times like "1 hr 15 mins", "45 minutes", "1h30m",
+// "1:30" or "PT45M". A bare number is taken as minutes, and ranges like "20-25 mins" as their upper bound.
+func ParseDuration(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(normalizeFractions(s))
+	if s == "" {
+		return 0, false
+	}
+
+	if m := isoDuration.FindStringSubmatch(s); m != nil && len(s) > 1 && !strings.HasSuffix(strings.ToUpper(s), "T") {
+		var total time.Duration
+		for n, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+			if m[n+1] != "" {
+				value, _ := strconv.ParseFloat(m[n+1], 64)
+				total += time.Duration(value * float64(unit))
+			}
+		}
+		return total, true
+	}
+
+	if m := clockDuration.FindStringSubmatch(s); m != nil {
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, true
+	}
+
+	if m := bareDuration.FindStringSubmatch(s); m != nil {
+		minutes, ok := parseRange(m[1], m[2])
+		if !ok {
+			return 0, false
+		}
+		return time.Duration(minutes * float64(time.Minute)), true
+	}
+
+	s = letterDigit.ReplaceAllString(s, "$1 $2")
+	matches := durationPart.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+
+	// parts separated by "to" or a dash, as in "5 mins to 10 mins", are a range and count as its upper bound
+	var longest, current, unit time.Duration
+	end := 0
+	for _, m := range matches {
+		if rangeSeparator.MatchString(s[end:m[0]]) {
+			longest, current = max(longest, current), 0
+		}
+		lower, upper := s[m[2]:m[3]], ""
+		if m[4] >= 0 {
+			upper = s[m[4]:m[5]]
+		}
+		value, ok := parseRange(lower, upper)
+		if !ok {
+			return 0, false
+		}
+		unit = durationUnits[strings.ToLower(s[m[6]:m[7]])[0]]
+		current += time.Duration(value * float64(unit))
+		end = m[1]
+	}
+
+	// a trailing bare number after hours is minutes, e.g. "2 hours 30"
+	if m := bareDuration.FindStringSubmatch(strings.TrimSpace(s[end:])); m != nil && m[2] == "" && unit == time.Hour {
+		if minutes, ok := parseNumber(m[1]); ok {
+			current += time.Duration(minutes * float64(time.Minute))
+		}
+	}
+	return max(longest, current), true
+}
+
+// parseRange parses a number or a range of numbers, returning its upper bound
+func parseRange(lower, upper string) (float64, bool) {
+	value, ok := parseNumber(lower)
+	if !ok {
+		return 0, false
+	}
+	if upper != "" {
+		if u, ok := parseNumber(upper); ok && u > value {
+			value = u
+		}
+	}
+	return value, true
+}
+
+// FormatDuration renders a duration the way Paprika shows times, e.g. "1 hr 15 mins".
+// Durations are rounded up to the minute.
+func FormatDuration(d time.Duration) string {
+	minutes := int(math.Ceil(d.Minutes()))
+	hours, minutes := minutes/60, minutes%60
+
+	var parts []string
+	switch {
+	case hours == 1:
+		parts = append(parts, "1 hr")
+	case hours > 1:
+		parts = append(parts, fmt.Sprintf("%d hrs", hours))
+	}
+	switch {
+	case minutes == 1:
+		parts = append(parts, "1 min")
+	case minutes > 1 || hours == 0:
+		parts = append(parts, fmt.Sprintf("%d mins", minutes))
+	}
+	return strings.Join(parts, " ")
+}
+
+// PrepDuration returns the recipe's prep time, if it can be parsed
+func (r *Recipe) PrepDuration() (time.Duration, bool) {
+	return ParseDuration(r.PrepTime)
+}
+
+// CookDuration returns the recipe's cook time, if it can be parsed
+func (r *Recipe) CookDuration() (time.Duration, bool) {
+	return ParseDuration(r.CookTime)
+}
+
+// TotalDuration returns how long the recipe takes: its total time, or else its prep and cook times added up
+func (r *Recipe) TotalDuration() (time.Duration, bool) {
+	if total, ok := ParseDuration(r.TotalTime); ok {
+		return total, true
+	}
+
+	prep, prepOK := r.PrepDuration()
+	cook, cookOK := r.CookDuration()
+	return prep + cook, prepOK || cookOK
+}
@@ -0,0 +1,81 @@
+package paprika_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"1 hr 15 mins":        75 * time.Minute,
+		"45 minutes":          45 * time.Minute,
+		"1h30m":               90 * time.Minute,
+		"1 1/2 hours":         90 * time.Minute,
+		"½ hour":              30 * time.Minute,
+		"20-25 mins":          25 * time.Minute,
+		"2 days":              48 * time.Hour,
+		"PT45M":               45 * time.Minute,
+		"PT1H30M":             90 * time.Minute,
+		"P1DT2H":              26 * time.Hour,
+		"1:15":                75 * time.Minute,
+		"30":                  30 * time.Minute,
+		"about 10 min":        10 * time.Minute,
+		"90 sec":              90 * time.Second,
+		"Overnight (8 hrs)":   8 * time.Hour,
+		"10-15":               15 * time.Minute,
+		"10 to 15":            15 * time.Minute,
+		"5 mins to 10 mins":   10 * time.Minute,
+		"1 hr - 1 hr 30 mins": 90 * time.Minute,
+		"2 hours 30":          150 * time.Minute,
+		"1 hr 15":             75 * time.Minute,
+	}
+	for s, want := range tests {
+		got, ok := paprika.ParseDuration(s)
+		if assert.True(t, ok, s) {
+			assert.Equal(t, want, got, s)
+		}
+	}
+
+	for _, s := range []string{"", "overnight", "PT", "P", "1 2", "10-15-20"} {
+		_, ok := paprika.ParseDuration(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "45 mins", paprika.FormatDuration(45*time.Minute))
+	assert.Equal(t, "1 hr", paprika.FormatDuration(time.Hour))
+	assert.Equal(t, "1 hr 1 min", paprika.FormatDuration(61*time.Minute))
+	assert.Equal(t, "2 hrs 15 mins", paprika.FormatDuration(135*time.Minute))
+	assert.Equal(t, "1 min", paprika.FormatDuration(30*time.Second))
+}
+
+func TestSaveRecipeKeepsTotalTime(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+
+	// a missing total time isn't stored, but computed from the prep and cook times when read
+	saved, err := client.SaveRecipe(context.Background(), paprika.Recipe{Name: "Soup", PrepTime: "15 mins", CookTime: "1 hr"})
+	require.NoError(t, err)
+	assert.Empty(t, saved.TotalTime)
+
+	saved.CookTime = "3 hrs"
+	saved, err = client.SaveRecipe(context.Background(), *saved)
+	require.NoError(t, err)
+	assert.Empty(t, saved.TotalTime)
+	total, ok := saved.TotalDuration()
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Hour+15*time.Minute, total)
+
+	saved, err = client.SaveRecipe(context.Background(), paprika.Recipe{Name: "Stew", PrepTime: "15 mins", TotalTime: "3 hours"})
+	require.NoError(t, err)
+	assert.Equal(t, "3 hours", saved.TotalTime)
+}
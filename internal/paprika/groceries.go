@@ -0,0 +1,169 @@
+package paprika
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GroceryList is one of the user's grocery lists; most accounts only have the default one
+type GroceryList struct {
+	UID           string `json:"uid"`
+	Name          string `json:"name"`
+	OrderFlag     int    `json:"order_flag"`
+	IsDefault     bool   `json:"is_default"`
+	RemindersList string `json:"reminders_list"`
+}
+
+// GroceryLists is a list of grocery lists
+type GroceryLists []GroceryList
+
+// Default returns the default grocery list, falling back to the first list
+func (l GroceryLists) Default() (GroceryList, bool) {
+	for _, list := range l {
+		if list.IsDefault {
+			return list, true
+		}
+	}
+	if len(l) > 0 {
+		return l[0], true
+	}
+	return GroceryList{}, false
+}
+
+// Find returns the grocery list with the given name, ignoring case
+func (l GroceryLists) Find(name string) (GroceryList, bool) {
+	name = strings.TrimSpace(name)
+	for _, list := range l {
+		if strings.EqualFold(list.Name, name) {
+			return list, true
+		}
+	}
+	return GroceryList{}, false
+}
+
+// GroceryItem is a single entry on a grocery list
+type GroceryItem struct {
+	UID         string `json:"uid"`
+	RecipeUID   string `json:"recipe_uid"`
+	Name        string `json:"name"`
+	OrderFlag   int    `json:"order_flag"`
+	Purchased   bool   `json:"purchased"`
+	Aisle       string `json:"aisle"`
+	Ingredient  string `json:"ingredient"`
+	Recipe      string `json:"recipe"`
+	Instruction string `json:"instruction"`
+	Quantity    string `json:"quantity"`
+	Separate    bool   `json:"separate"`
+	AisleUID    string `json:"aisle_uid"`
+	ListUID     string `json:"list_uid"`
+	// Deleted marks an item for removal when it is uploaded.
+	// As far as I can tell, this is how the apps remove items from synced collections.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// ListGroceryLists retrieves all grocery lists
+func (c *Client) ListGroceryLists(ctx context.Context) (GroceryLists, error) {
+	var lists GroceryLists
+	if err := c.get(ctx, "/api/v2/sync/grocerylists/", "grocery lists", &lists); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+// ListGroceryItems retrieves the items of all grocery lists
+func (c *Client) ListGroceryItems(ctx context.Context) ([]GroceryItem, error) {
+	var items []GroceryItem
+	if err := c.get(ctx, "/api/v2/sync/groceries/", "groceries", &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// SaveGroceryItems creates or updates grocery items. Items without a UID are created,
+// and items without a list are added to the default grocery list.
+func (c *Client) SaveGroceryItems(ctx context.Context, items ...GroceryItem) ([]GroceryItem, error) {
+	var defaultList *GroceryList
+	saved := make([]GroceryItem, 0, len(items))
+	for _, item := range items {
+		if item.UID == "" {
+			item.UID = strings.ToUpper(uuid.New().String())
+		}
+		if item.Ingredient == "" {
+			item.Ingredient = item.Name
+		}
+		if item.ListUID == "" {
+			if defaultList == nil {
+				lists, err := c.ListGroceryLists(ctx)
+				if err != nil {
+					return nil, err
+				}
+				list, ok := lists.Default()
+				if !ok {
+					return nil, fmt.Errorf("no grocery list to add %q to", item.Name)
+				}
+				defaultList = &list
+			}
+			item.ListUID = defaultList.UID
+		}
+		saved = append(saved, item)
+	}
+
+	if err := c.uploadJSON(ctx, "/api/v2/sync/groceries/", "save groceries", saved); err != nil {
+		return nil, err
+	}
+
+	defer c.notify(ctx)
+
+	return saved, nil
+}
+
+// CheckGroceryItem marks a grocery item as purchased, or as not purchased again
+func (c *Client) CheckGroceryItem(ctx context.Context, uid string, purchased bool) (*GroceryItem, error) {
+	item, err := c.findGroceryItem(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Purchased = purchased
+	saved, err := c.SaveGroceryItems(ctx, *item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved[0], nil
+}
+
+// RemoveGroceryItem removes an item from its grocery list
+func (c *Client) RemoveGroceryItem(ctx context.Context, uid string) (*GroceryItem, error) {
+	item, err := c.findGroceryItem(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Deleted = true
+	if _, err := c.SaveGroceryItems(ctx, *item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (c *Client) findGroceryItem(ctx context.Context, uid string) (*GroceryItem, error) {
+	items, err := c.ListGroceryItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if strings.EqualFold(item.UID, uid) {
+			return &item, nil
+		}
+	}
+
+	return nil, fmt.Errorf("grocery item %s not found", uid)
+}
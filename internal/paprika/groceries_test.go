@@ -0,0 +1,54 @@
+package paprika_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroceries(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutGroceryList(paprika.GroceryList{UID: "WEEKEND", Name: "Weekend"})
+	srv.PutGroceryList(paprika.GroceryList{UID: "MAIN", Name: "My Grocery List", IsDefault: true})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	lists, err := client.ListGroceryLists(ctx)
+	require.NoError(t, err)
+	list, ok := lists.Find("weekend")
+	require.True(t, ok)
+	assert.Equal(t, "WEEKEND", list.UID)
+
+	saved, err := client.SaveGroceryItems(ctx,
+		paprika.GroceryItem{Name: "2 onions", Aisle: "Produce"},
+		paprika.GroceryItem{Name: "Beer", ListUID: "WEEKEND"},
+	)
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	assert.Equal(t, "MAIN", saved[0].ListUID)
+	assert.Equal(t, "2 onions", saved[0].Ingredient)
+	assert.Equal(t, "WEEKEND", saved[1].ListUID)
+
+	checked, err := client.CheckGroceryItem(ctx, saved[0].UID, true)
+	require.NoError(t, err)
+	assert.True(t, checked.Purchased)
+
+	_, err = client.RemoveGroceryItem(ctx, saved[1].UID)
+	require.NoError(t, err)
+
+	items, err := client.ListGroceryItems(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "2 onions", items[0].Name)
+	assert.True(t, items[0].Purchased)
+
+	_, err = client.CheckGroceryItem(ctx, "MISSING", true)
+	assert.Error(t, err)
+}
@@ -0,0 +1,321 @@
+package paprika
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Quantity is the amount of an ingredient, e.g. 1.5 for "1 1/2" or 2 to 3 for "2-3"
+type Quantity struct {
+	Value float64 `json:"value"`
+	// Max is the upper bound of a range like "2-3", or zero if the quantity is a single amount
+	Max float64 `json:"max,omitempty"`
+}
+
+// IsRange reports whether the quantity is a range like "2-3"
+func (q Quantity) IsRange() bool {
+	return q.Max > q.Value
+}
+
+// Ingredient is a line of a recipe's ingredients, parsed into its parts
+type Ingredient struct {
+	// Raw is the line as it was written
+	Raw string `json:"raw"`
+	// Header marks section headers like "For the sauce:"; their Name is the title of the section
+	Header bool `json:"header,omitempty"`
+	// Section is the title of the section the ingredient is listed under, if any
+	Section string `json:"section,omitempty"`
+	// Quantity is nil if the line doesn't start with an amount, e.g. "salt to taste"
+	Quantity *Quantity `json:"quantity,omitempty"`
+	// Unit is the canonical name of the unit, e.g. "tbsp" for "Tablespoons", or empty for countable items
+	Unit string `json:"unit,omitempty"`
+	Name string `json:"name"`
+	// Note holds preparation notes like "finely chopped"
+	Note     string `json:"note,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// unicodeFractions are replaced by their ASCII equivalent before parsing
+var unicodeFractions = map[rune]string{
+	'½': "1/2", '⅓': "1/3", '⅔': "2/3", '¼': "1/4", '¾': "3/4",
+	'⅕': "1/5", '⅖': "2/5", '⅗': "3/5", '⅘': "4/5", '⅙': "1/6",
+	'⅚': "5/6", '⅛': "1/8", '⅜': "3/8", '⅝': "5/8", '⅞': "7/8",
+}
+
+// unitAliases maps the ways units are written, lowercased and without dots, to their canonical name
+var unitAliases = map[string]string{
+	"tsp": "tsp", "tsps": "tsp", "teaspoon": "tsp", "teaspoons": "tsp",
+	"tbsp": "tbsp", "tbsps": "tbsp", "tbs": "tbsp", "tbl": "tbsp", "tablespoon": "tbsp", "tablespoons": "tbsp",
+	"cup": "cup", "cups": "cup", "c": "cup",
+	"fl oz": "fl oz", "fluid ounce": "fl oz", "fluid ounces": "fl oz", "floz": "fl oz",
+	"pint": "pint", "pints": "pint", "pt": "pint", "pts": "pint",
+	"quart": "quart", "quarts": "quart", "qt": "quart", "qts": "quart",
+	"gallon": "gallon", "gallons": "gallon", "gal": "gallon",
+	"ml": "ml", "milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml",
+	"cl": "cl", "centiliter": "cl", "centiliters": "cl", "centilitre": "cl", "centilitres": "cl",
+	"dl": "dl", "deciliter": "dl", "deciliters": "dl", "decilitre": "dl", "decilitres": "dl",
+	"l": "l", "liter": "l", "liters": "l", "litre": "l", "litres": "l",
+	"g": "g", "gr": "g", "gram": "g", "grams": "g", "gramme": "g", "grammes": "g",
+	"kg": "kg", "kgs": "kg", "kilogram": "kg", "kilograms": "kg", "kilo": "kg", "kilos": "kg",
+	"mg": "mg", "milligram": "mg", "milligrams": "mg",
+	"oz": "oz", "ounce": "oz", "ounces": "oz",
+	"lb": "lb", "lbs": "lb", "pound": "lb", "pounds": "lb",
+	"pinch": "pinch", "pinches": "pinch",
+	"dash": "dash", "dashes": "dash",
+	"clove": "clove", "cloves": "clove",
+	"can": "can", "cans": "can", "tin": "can", "tins": "can",
+	"package": "package", "packages": "package", "pkg": "package", "packet": "package", "packets": "package",
+	"stick": "stick", "sticks": "stick",
+	"slice": "slice", "slices": "slice",
+	"bunch": "bunch", "bunches": "bunch",
+	"sprig": "sprig", "sprigs": "sprig",
+	"handful": "handful", "handfuls": "handful",
+	"piece": "piece", "pieces": "piece",
+}
+
+// number matches integers, decimals, fractions and mixed numbers. A comma followed by three digits
+// separates thousands, as in "1,000"; followed by one or two digits it is a decimal point, as in "1,5".
+const number = `\d+\s+\d+/\d+|\d+/\d+|\d{1,3}(?:,\d{3})+\b(?:\.\d+)?|\d+(?:\.\d+|,\d{1,2}\b)?`
+
+var (
+	quantityPattern = regexp.MustCompile(`^(` + number + `)(?:\s*[-–—]\s*|\s+(?:to|or)\s+)?(` + number + `)?`)
+	unitWord        = regexp.MustCompile(`^([A-Za-z]+)\.?(?:\s+|$)`)
+	parenthetical   = regexp.MustCompile(`\s*\(([^)]*)\)`)
+	optionalWord    = regexp.MustCompile(`(?i)\boptional\b`)
+	orMore          = regexp.MustCompile(`(?i)^or\s+more\b`)
+	thousands       = regexp.MustCompile(`^\d{1,3}(?:,\d{3})+(?:\.\d+)?$`)
+)
+
+// ParseIngredients parses a recipe's newline-separated ingredients, skipping blank lines.
+// Ingredients following a section header have their Section set to its title.
+func ParseIngredients(text string) []Ingredient {
+	var ingredients []Ingredient
+	section := ""
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		ingredient := ParseIngredient(line)
+		if ingredient.Header {
+			section = ingredient.Name
+		} else {
+			ingredient.Section = section
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+	return ingredients
+}
+
+// ParseIngredient parses a single ingredient line like "1 1/2 cups flour, sifted"
+func ParseIngredient(line string) Ingredient {
+	ingredient := Ingredient{Raw: line}
+	rest := normalizeFractions(strings.TrimSpace(line))
+
+	if isHeader(rest) {
+		ingredient.Header = true
+		ingredient.Name = strings.TrimSpace(strings.TrimSuffix(rest, ":"))
+		return ingredient
+	}
+
+	ingredient.Quantity, rest = parseQuantity(rest)
+	var qualifier string
+	if ingredient.Quantity != nil {
+		// "2 or more eggs" is kept as a note so the name is just "eggs"
+		rest = strings.TrimSpace(rest)
+		if m := orMore.FindString(rest); m != "" {
+			qualifier, rest = strings.ToLower(m), rest[len(m):]
+		}
+		// a size like "1 (14 oz) can tomatoes" is kept as a note
+		var size string
+		if m := parenthetical.FindStringSubmatchIndex(rest); m != nil && m[0] == 0 {
+			size, rest = strings.TrimSpace(rest[m[2]:m[3]]), rest[m[1]:]
+		}
+		ingredient.Unit, rest = parseUnit(strings.TrimSpace(rest))
+		if size != "" {
+			rest += " (" + size + ")"
+		}
+	}
+
+	ingredient.Name, ingredient.Note, ingredient.Optional = parseName(rest)
+	if qualifier != "" && ingredient.Note != "" {
+		ingredient.Note = qualifier + ", " + ingredient.Note
+	} else if qualifier != "" {
+		ingredient.Note = qualifier
+	}
+	return ingredient
+}
+
+// isHeader reports whether a line is a section header: Paprika shows lines ending in a colon,
+// or written in capitals, as headers
+func isHeader(line string) bool {
+	if strings.HasSuffix(line, ":") && !startsWithDigit(line) {
+		return true
+	}
+
+	letters := false
+	for _, r := range line {
+		if unicode.IsLower(r) || unicode.IsDigit(r) {
+			return false
+		}
+		letters = letters || unicode.IsLetter(r)
+	}
+	return letters
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// normalizeFractions replaces unicode fractions like "1½" with "1 1/2"
+func normalizeFractions(s string) string {
+	var sb strings.Builder
+	var last rune
+	for _, r := range s {
+		if fraction, ok := unicodeFractions[r]; ok {
+			if unicode.IsDigit(last) {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(fraction)
+			last = r
+			continue
+		}
+		if r == '⁄' {
+			r = '/'
+		}
+		sb.WriteRune(r)
+		last = r
+	}
+	return sb.String()
+}
+
+// parseQuantity parses the amount at the start of s, returning the rest of the line.
+// "a" or "an" followed by a unit, as in "a pinch of salt", count as one.
+func parseQuantity(s string) (*Quantity, string) {
+	if m := quantityPattern.FindStringSubmatch(s); m != nil {
+		rest := s[len(m[0]):]
+		// "2 or more" matches a separator without a second number, which ParseIngredient keeps as a note
+		if m[2] == "" {
+			rest = s[len(m[1]):]
+		}
+
+		value, ok := parseNumber(m[1])
+		if !ok {
+			return nil, s
+		}
+		q := &Quantity{Value: value}
+		if m[2] != "" {
+			if max, ok := parseNumber(m[2]); ok && max > value {
+				q.Max = max
+			}
+		}
+		return q, rest
+	}
+
+	for _, article := range []string{"a ", "an "} {
+		if len(s) > len(article) && strings.EqualFold(s[:len(article)], article) {
+			if unit, _ := parseUnit(s[len(article):]); unit != "" {
+				return &Quantity{Value: 1}, s[len(article):]
+			}
+		}
+	}
+
+	return nil, s
+}
+
+// parseNumber parses integers, decimals, fractions and mixed numbers like "1 1/2"
+func parseNumber(s string) (float64, bool) {
+	var total float64
+	for _, part := range strings.Fields(s) {
+		if numerator, denominator, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.ParseFloat(numerator, 64)
+			if err != nil {
+				return 0, false
+			}
+			d, err := strconv.ParseFloat(denominator, 64)
+			if err != nil || d == 0 {
+				return 0, false
+			}
+			total += n / d
+			continue
+		}
+
+		if thousands.MatchString(part) {
+			part = strings.ReplaceAll(part, ",", "")
+		} else {
+			part = strings.Replace(part, ",", ".", 1)
+		}
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, false
+		}
+		total += value
+	}
+	return total, true
+}
+
+// parseUnit parses the unit at the start of s, skipping a following "of", and returns the rest of the line
+func parseUnit(s string) (string, string) {
+	first := unitWord.FindStringSubmatch(s)
+	if first != nil {
+		// "T" and "t" are common shorthands for tablespoons and teaspoons
+		switch first[1] {
+		case "T":
+			return "tbsp", skipOf(s[len(first[0]):])
+		case "t":
+			return "tsp", skipOf(s[len(first[0]):])
+		}
+
+		// try two-word units like "fl oz" first
+		if second := unitWord.FindStringSubmatch(s[len(first[0]):]); second != nil {
+			if unit, ok := unitAliases[strings.ToLower(first[1]+" "+second[1])]; ok {
+				return unit, skipOf(s[len(first[0])+len(second[0]):])
+			}
+		}
+		if unit, ok := unitAliases[strings.ToLower(first[1])]; ok {
+			return unit, skipOf(s[len(first[0]):])
+		}
+	}
+
+	return "", s
+}
+
+func skipOf(s string) string {
+	if len(s) > 3 && strings.EqualFold(s[:3], "of ") {
+		return s[3:]
+	}
+	return s
+}
+
+// parseName splits the rest of a line into the ingredient's name and its notes, which follow
+// a comma or are in parentheses
+func parseName(s string) (name, note string, optional bool) {
+	var notes []string
+	for _, m := range parenthetical.FindAllStringSubmatch(s, -1) {
+		notes = append(notes, strings.TrimSpace(m[1]))
+	}
+	s = parenthetical.ReplaceAllString(s, "")
+
+	name, rest, _ := strings.Cut(s, ",")
+	notes = append(notes, strings.TrimSpace(rest))
+
+	var kept []string
+	for _, n := range notes {
+		if optionalWord.MatchString(n) {
+			optional = true
+			n = strings.Trim(optionalWord.ReplaceAllString(n, ""), " ,;")
+		}
+		if n != "" {
+			kept = append(kept, n)
+		}
+	}
+
+	return strings.TrimSpace(name), strings.Join(kept, ", "), optional
+}
+
+// ParsedIngredients returns the recipe's ingredients, parsed into their parts
+func (r *Recipe) ParsedIngredients() []Ingredient {
+	return ParseIngredients(r.Ingredients)
+}
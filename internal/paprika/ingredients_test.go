@@ -0,0 +1,58 @@
+package paprika_test
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIngredient(t *testing.T) {
+	tests := []struct {
+		line string
+		want paprika.Ingredient
+	}{
+		{"2 cups flour", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 2}, Unit: "cup", Name: "flour"}},
+		{"1 1/2 Tbsp. olive oil", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1.5}, Unit: "tbsp", Name: "olive oil"}},
+		{"1½ tsp salt", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1.5}, Unit: "tsp", Name: "salt"}},
+		{"¾ cup of sugar", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 0.75}, Unit: "cup", Name: "sugar"}},
+		{"2-3 cloves garlic, minced", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 2, Max: 3}, Unit: "clove", Name: "garlic", Note: "minced"}},
+		{"1 to 2 T honey", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1, Max: 2}, Unit: "tbsp", Name: "honey"}},
+		{"200g butter (softened)", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 200}, Unit: "g", Name: "butter", Note: "softened"}},
+		{"1,5 kg potatoes", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1.5}, Unit: "kg", Name: "potatoes"}},
+		{"1,000 g flour", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1000}, Unit: "g", Name: "flour"}},
+		{"1,5 kg", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1.5}, Unit: "kg"}},
+		{"1,250.5 ml milk", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1250.5}, Unit: "ml", Name: "milk"}},
+		{"2 fl oz cream", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 2}, Unit: "fl oz", Name: "cream"}},
+		{"1 (14 oz) can diced tomatoes", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1}, Unit: "can", Name: "diced tomatoes", Note: "14 oz"}},
+		{"2 or more eggs", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 2}, Name: "eggs", Note: "or more"}},
+		{"1 or more cups stock, hot", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1}, Unit: "cup", Name: "stock", Note: "or more, hot"}},
+		{"3 large eggs, beaten", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 3}, Name: "large eggs", Note: "beaten"}},
+		{"a pinch of nutmeg", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1}, Unit: "pinch", Name: "nutmeg"}},
+		{"1 tsp chili flakes (optional)", paprika.Ingredient{Quantity: &paprika.Quantity{Value: 1}, Unit: "tsp", Name: "chili flakes", Optional: true}},
+		{"fresh parsley, chopped, optional", paprika.Ingredient{Name: "fresh parsley", Note: "chopped", Optional: true}},
+		{"salt and pepper to taste", paprika.Ingredient{Name: "salt and pepper to taste"}},
+		{"For the sauce:", paprika.Ingredient{Header: true, Name: "For the sauce"}},
+		{"FROSTING", paprika.Ingredient{Header: true, Name: "FROSTING"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			tt.want.Raw = tt.line
+			assert.Equal(t, tt.want, paprika.ParseIngredient(tt.line))
+		})
+	}
+}
+
+func TestParseIngredientsSections(t *testing.T) {
+	recipe := paprika.Recipe{Ingredients: "2 cups flour\n\nFor the glaze:\n1 cup powdered sugar\n2 tbsp milk\n"}
+
+	ingredients := recipe.ParsedIngredients()
+	require.Len(t, ingredients, 4)
+	assert.Empty(t, ingredients[0].Section)
+	assert.True(t, ingredients[1].Header)
+	assert.Equal(t, "For the glaze", ingredients[2].Section)
+	assert.Equal(t, "For the glaze", ingredients[3].Section)
+	assert.Equal(t, "milk", ingredients[3].Name)
+}
@@ -0,0 +1,168 @@
+package paprika
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MealType is the slot of the day a meal is planned for
+type MealType int
+
+const (
+	Breakfast MealType = iota
+	Lunch
+	Dinner
+	Snack
+)
+
+var mealTypeNames = []string{"breakfast", "lunch", "dinner", "snack"}
+
+func (t MealType) String() string {
+	if t < 0 || int(t) >= len(mealTypeNames) {
+		return fmt.Sprintf("meal type %d", int(t))
+	}
+	return mealTypeNames[t]
+}
+
+// ParseMealType parses the name of a meal type, e.g. "dinner"
+func ParseMealType(name string) (MealType, error) {
+	for i, n := range mealTypeNames {
+		if strings.EqualFold(strings.TrimSpace(name), n) {
+			return MealType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown meal type %q, expected one of %s", name, strings.Join(mealTypeNames, ", "))
+}
+
+// Meal is an entry in the meal planner. It either references a recipe or is a free-text meal with just a name.
+type Meal struct {
+	UID       string   `json:"uid"`
+	RecipeUID string   `json:"recipe_uid"`
+	Date      string   `json:"date"`
+	Type      MealType `json:"type"`
+	Name      string   `json:"name"`
+	OrderFlag int      `json:"order_flag"`
+	TypeUID   string   `json:"type_uid"`
+	// Deleted marks a meal for removal when it is uploaded
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// Day returns the date the meal is planned for, at midnight UTC
+func (m *Meal) Day() (time.Time, error) {
+	day, err := time.Parse(DateLayout, m.Date)
+	if err != nil {
+		// some clients only send the date
+		day, err = time.Parse(time.DateOnly, m.Date)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid meal date %q: %w", m.Date, err)
+	}
+	return day.Truncate(24 * time.Hour), nil
+}
+
+// Meals is a list of meal planner entries
+type Meals []Meal
+
+// Between returns the meals planned from start through end (inclusive, compared by day),
+// sorted by date and meal type
+func (m Meals) Between(start, end time.Time) Meals {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	var result Meals
+	for _, meal := range m {
+		day, err := meal.Day()
+		if err != nil || day.Before(start) || day.After(end) {
+			continue
+		}
+		result = append(result, meal)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date < result[j].Date
+		}
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].OrderFlag < result[j].OrderFlag
+	})
+	return result
+}
+
+// ListMeals retrieves all meal planner entries
+func (c *Client) ListMeals(ctx context.Context) (Meals, error) {
+	var meals Meals
+	if err := c.get(ctx, "/api/v2/sync/meals/", "meals", &meals); err != nil {
+		return nil, err
+	}
+
+	return meals, nil
+}
+
+// SaveMeals creates or updates meal planner entries. Meals without a UID are created.
+func (c *Client) SaveMeals(ctx context.Context, meals ...Meal) (Meals, error) {
+	saved := make(Meals, 0, len(meals))
+	for _, meal := range meals {
+		if meal.UID == "" {
+			meal.UID = strings.ToUpper(uuid.New().String())
+		}
+		if meal.RecipeUID == "" && meal.Name == "" {
+			return nil, fmt.Errorf("meal %s needs a recipe or a name", meal.UID)
+		}
+		if _, err := meal.Day(); err != nil {
+			return nil, err
+		}
+		saved = append(saved, meal)
+	}
+
+	if err := c.uploadJSON(ctx, "/api/v2/sync/meals/", "save meals", saved); err != nil {
+		return nil, err
+	}
+
+	defer c.notify(ctx)
+
+	return saved, nil
+}
+
+// ScheduleMeal adds a meal to the planner on the given day
+func (c *Client) ScheduleMeal(ctx context.Context, day time.Time, mealType MealType, recipeUID, name string) (*Meal, error) {
+	saved, err := c.SaveMeals(ctx, Meal{
+		RecipeUID: strings.ToUpper(recipeUID),
+		Name:      name,
+		Type:      mealType,
+		Date:      time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC).Format(DateLayout),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved[0], nil
+}
+
+// DeleteMeal removes a meal from the planner
+func (c *Client) DeleteMeal(ctx context.Context, uid string) (*Meal, error) {
+	meals, err := c.ListMeals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, meal := range meals {
+		if !strings.EqualFold(meal.UID, uid) {
+			continue
+		}
+
+		meal.Deleted = true
+		if _, err := c.SaveMeals(ctx, meal); err != nil {
+			return nil, err
+		}
+		return &meal, nil
+	}
+
+	return nil, fmt.Errorf("meal %s not found", uid)
+}
@@ -0,0 +1,57 @@
+package paprika_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMealType(t *testing.T) {
+	mealType, err := paprika.ParseMealType(" Dinner ")
+	require.NoError(t, err)
+	assert.Equal(t, paprika.Dinner, mealType)
+	assert.Equal(t, "dinner", mealType.String())
+
+	_, err = paprika.ParseMealType("brunch")
+	assert.Error(t, err)
+}
+
+func TestMeals(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutMeal(paprika.Meal{UID: "OLD", Name: "Leftovers", Date: "2025-03-01 00:00:00", Type: paprika.Lunch})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	monday := time.Date(2025, 3, 10, 18, 30, 0, 0, time.Local)
+	dinner, err := client.ScheduleMeal(ctx, monday, paprika.Dinner, "abc", "Chili")
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-10 00:00:00", dinner.Date)
+	assert.Equal(t, "ABC", dinner.RecipeUID)
+
+	_, err = client.ScheduleMeal(ctx, monday, paprika.Breakfast, "", "Toast")
+	require.NoError(t, err)
+
+	_, err = client.ScheduleMeal(ctx, monday, paprika.Snack, "", "")
+	assert.Error(t, err)
+
+	meals, err := client.ListMeals(ctx)
+	require.NoError(t, err)
+	assert.Len(t, meals, 3)
+
+	week := meals.Between(monday, monday.AddDate(0, 0, 6))
+	require.Len(t, week, 2)
+	assert.Equal(t, "Toast", week[0].Name)
+	assert.Equal(t, "Chili", week[1].Name)
+
+	_, err = client.DeleteMeal(ctx, dinner.UID)
+	require.NoError(t, err)
+	assert.Len(t, srv.Meals(), 2)
+}
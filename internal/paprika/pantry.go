@@ -0,0 +1,128 @@
+package paprika
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PantryItem is an ingredient the user has at home
+type PantryItem struct {
+	UID            string `json:"uid"`
+	Ingredient     string `json:"ingredient"`
+	Quantity       string `json:"quantity"`
+	Aisle          string `json:"aisle"`
+	AisleUID       string `json:"aisle_uid"`
+	PurchaseDate   string `json:"purchase_date"`
+	ExpirationDate string `json:"expiration_date"`
+	HasExpiration  bool   `json:"has_expiration"`
+	InStock        bool   `json:"in_stock"`
+	// Deleted marks an item for removal when it is uploaded
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// Expiration returns the expiration date of the item, if it has one
+func (p *PantryItem) Expiration() (time.Time, bool) {
+	if !p.HasExpiration || p.ExpirationDate == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range []string{DateLayout, time.DateOnly} {
+		if t, err := time.Parse(layout, p.ExpirationDate); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// PantryItems is a list of pantry items
+type PantryItems []PantryItem
+
+// InStock returns the items that are currently in stock
+func (p PantryItems) InStock() PantryItems {
+	var result PantryItems
+	for _, item := range p {
+		if item.InStock {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// ExpiringBefore returns the in-stock items that expire before the given time,
+// including items that have already expired, soonest first
+func (p PantryItems) ExpiringBefore(t time.Time) PantryItems {
+	var result PantryItems
+	for _, item := range p.InStock() {
+		if expiration, ok := item.Expiration(); ok && expiration.Before(t) {
+			result = append(result, item)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, _ := result[i].Expiration()
+		b, _ := result[j].Expiration()
+		return a.Before(b)
+	})
+	return result
+}
+
+// ListPantryItems retrieves all pantry items
+func (c *Client) ListPantryItems(ctx context.Context) (PantryItems, error) {
+	var items PantryItems
+	if err := c.get(ctx, "/api/v2/sync/pantry/", "pantry", &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// SavePantryItems creates or updates pantry items. Items without a UID are created.
+func (c *Client) SavePantryItems(ctx context.Context, items ...PantryItem) (PantryItems, error) {
+	saved := make(PantryItems, 0, len(items))
+	for _, item := range items {
+		if strings.TrimSpace(item.Ingredient) == "" {
+			return nil, fmt.Errorf("pantry item %s needs an ingredient", item.UID)
+		}
+		if item.UID == "" {
+			item.UID = strings.ToUpper(uuid.New().String())
+		}
+		item.HasExpiration = item.ExpirationDate != ""
+		saved = append(saved, item)
+	}
+
+	if err := c.uploadJSON(ctx, "/api/v2/sync/pantry/", "save pantry", saved); err != nil {
+		return nil, err
+	}
+
+	defer c.notify(ctx)
+
+	return saved, nil
+}
+
+// DeletePantryItem removes an item from the pantry
+func (c *Client) DeletePantryItem(ctx context.Context, uid string) (*PantryItem, error) {
+	items, err := c.ListPantryItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if !strings.EqualFold(item.UID, uid) {
+			continue
+		}
+
+		item.Deleted = true
+		if _, err := c.SavePantryItems(ctx, item); err != nil {
+			return nil, err
+		}
+		return &item, nil
+	}
+
+	return nil, fmt.Errorf("pantry item %s not found", uid)
+}
@@ -0,0 +1,58 @@
+package paprika_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPantryItemsExpiringBefore(t *testing.T) {
+	items := paprika.PantryItems{
+		{UID: "MILK", Ingredient: "milk", InStock: true, HasExpiration: true, ExpirationDate: "2025-03-12 00:00:00"},
+		{UID: "EGGS", Ingredient: "eggs", InStock: true, HasExpiration: true, ExpirationDate: "2025-03-05"},
+		{UID: "CHEESE", Ingredient: "cheese", InStock: false, HasExpiration: true, ExpirationDate: "2025-03-01 00:00:00"},
+		{UID: "RICE", Ingredient: "rice", InStock: true},
+		{UID: "YOGURT", Ingredient: "yogurt", InStock: true, HasExpiration: true, ExpirationDate: "2025-04-01 00:00:00"},
+	}
+
+	expiring := items.ExpiringBefore(time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+	require.Len(t, expiring, 2)
+	assert.Equal(t, "EGGS", expiring[0].UID)
+	assert.Equal(t, "MILK", expiring[1].UID)
+	assert.Len(t, items.InStock(), 4)
+}
+
+func TestPantry(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	saved, err := client.SavePantryItems(ctx,
+		paprika.PantryItem{Ingredient: "eggs", Quantity: "12", InStock: true, ExpirationDate: "2025-03-05 00:00:00"},
+		paprika.PantryItem{Ingredient: "flour", InStock: true},
+	)
+	require.NoError(t, err)
+	require.Len(t, saved, 2)
+	assert.True(t, saved[0].HasExpiration)
+	assert.False(t, saved[1].HasExpiration)
+
+	_, err = client.SavePantryItems(ctx, paprika.PantryItem{Quantity: "1"})
+	assert.Error(t, err)
+
+	items, err := client.ListPantryItems(ctx)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	_, err = client.DeletePantryItem(ctx, saved[1].UID)
+	require.NoError(t, err)
+	require.Len(t, srv.PantryItems(), 1)
+	assert.Equal(t, "eggs", srv.PantryItems()[0].Ingredient)
+}
@@ -0,0 +1,61 @@
+package paprikatest
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Names of the synced collections, as they appear in /api/v2/sync/{name}/
+const (
+	categories   = "categories"
+	groceryLists = "grocerylists"
+	groceries    = "groceries"
+	meals        = "meals"
+	pantry       = "pantry"
+)
+
+// collectionItem holds the fields shared by every object in a synced collection
+type collectionItem struct {
+	UID     string `json:"uid"`
+	Deleted bool   `json:"deleted"`
+}
+
+// put stores v as the item with the given uid in the named collection
+func (s *Server) put(name, uid string, v interface{}) {
+	raw, ok := v.(json.RawMessage)
+	if !ok {
+		raw, _ = json.Marshal(v)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.collections[name] == nil {
+		s.collections[name] = make(map[string]json.RawMessage)
+	}
+	s.collections[name][uid] = raw
+}
+
+// remove deletes the item with the given uid from the named collection
+func (s *Server) remove(name, uid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.collections[name], uid)
+}
+
+// list decodes all items of the named collection, sorted by UID, into out
+func (s *Server) list(name string, out interface{}) {
+	s.mu.Lock()
+	uids := make([]string, 0, len(s.collections[name]))
+	for uid := range s.collections[name] {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	items := make([]json.RawMessage, 0, len(uids))
+	for _, uid := range uids {
+		items = append(items, s.collections[name][uid])
+	}
+	s.mu.Unlock()
+
+	raw, _ := json.Marshal(items)
+	_ = json.Unmarshal(raw, out)
+}
@@ -0,0 +1,404 @@
+// Package paprikatest provides an in-process fake of the Paprika cloud sync API,
+// so that paprika.Client and everything built on top of it can be tested without a real account.
+package paprikatest
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+const (
+	// Username is the account email accepted by a Server created with NewServer
+	Username = "cook@example.com"
+	// Password is the account password accepted by a Server created with NewServer
+	Password = "paprika"
+	// Token is the bearer token handed out on a successful login
+	Token = "paprikatest-token"
+)
+
+// Failure describes an injected failure. The next Count requests matching Method and
+// the Path prefix are answered with Status and, if set, a Paprika-style error body.
+type Failure struct {
+	Method       string
+	Path         string
+	Status       int
+	ErrorCode    int
+	ErrorMessage string
+	Count        int
+}
+
+// Server is a fake Paprika sync server backed by an in-memory store
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	username      string
+	password      string
+	recipes       map[string]paprika.Recipe
+	collections   map[string]map[string]json.RawMessage
+	failures      []*Failure
+	notifications int
+	// requests are the requests the server received, as "METHOD /path"
+	requests []string
+}
+
+// NewServer starts a fake Paprika server that accepts the Username and Password credentials.
+// Callers should Close the server when finished.
+func NewServer() *Server {
+	s := &Server{
+		username:    Username,
+		password:    Password,
+		recipes:     make(map[string]paprika.Recipe),
+		collections: make(map[string]map[string]json.RawMessage),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/account/login", s.handleLogin)
+	mux.HandleFunc("GET /api/v2/sync/recipes", s.authenticated(s.handleListRecipes))
+	mux.HandleFunc("GET /api/v2/sync/recipe/{uid}/{$}", s.authenticated(s.handleGetRecipe))
+	mux.HandleFunc("POST /api/v2/sync/recipe/{uid}/{$}", s.authenticated(s.handleSaveRecipe))
+	for _, name := range []string{categories, groceryLists, groceries, meals, pantry} {
+		mux.HandleFunc(fmt.Sprintf("GET /api/v2/sync/%s/{$}", name), s.authenticated(s.handleListCollection(name)))
+		mux.HandleFunc(fmt.Sprintf("POST /api/v2/sync/%s/{$}", name), s.authenticated(s.handleSaveCollection(name)))
+	}
+	mux.HandleFunc("POST /api/v2/sync/notify", s.authenticated(s.handleNotify))
+
+	s.Server = httptest.NewServer(s.record(s.injectFailures(mux)))
+	return s
+}
+
+// NewClient returns a paprika.Client logged in to the fake server
+func (s *Server) NewClient() (*paprika.Client, error) {
+	return paprika.NewClient(paprika.NewClientOptions{
+		Username: Username,
+		Password: Password,
+		Version:  "test",
+		BaseURL:  s.URL,
+	})
+}
+
+// InjectFailure registers a failure for subsequent requests. A Count of zero fails a single request.
+func (s *Server) InjectFailure(f Failure) {
+	if f.Count == 0 {
+		f.Count = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// PutRecipe stores a recipe directly, bypassing the API. The hash is computed if it is empty.
+func (s *Server) PutRecipe(recipe paprika.Recipe) {
+	if recipe.Hash == "" {
+		recipe.Hash = hashOf(recipe)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recipes[recipe.UID] = recipe
+}
+
+// RemoveRecipe deletes a recipe from the store, as if it had been purged from the trash in-app
+func (s *Server) RemoveRecipe(uid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.recipes, uid)
+}
+
+// Recipe returns the stored recipe with the given UID
+func (s *Server) Recipe(uid string) (paprika.Recipe, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.recipes[uid]
+	return r, ok
+}
+
+// Recipes returns all stored recipes, sorted by UID
+func (s *Server) Recipes() []paprika.Recipe {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recipes := make([]paprika.Recipe, 0, len(s.recipes))
+	for _, r := range s.recipes {
+		recipes = append(recipes, r)
+	}
+	sort.Slice(recipes, func(i, j int) bool { return recipes[i].UID < recipes[j].UID })
+	return recipes
+}
+
+// PutCategory stores a category directly, bypassing the API
+func (s *Server) PutCategory(category paprika.Category) {
+	s.put(categories, category.UID, category)
+}
+
+// Categories returns all stored categories, sorted by UID
+func (s *Server) Categories() paprika.Categories {
+	var result paprika.Categories
+	s.list(categories, &result)
+	return result
+}
+
+// PutGroceryList stores a grocery list directly, bypassing the API
+func (s *Server) PutGroceryList(list paprika.GroceryList) {
+	s.put(groceryLists, list.UID, list)
+}
+
+// PutGroceryItem stores a grocery item directly, bypassing the API
+func (s *Server) PutGroceryItem(item paprika.GroceryItem) {
+	s.put(groceries, item.UID, item)
+}
+
+// GroceryItems returns all stored grocery items, sorted by UID
+func (s *Server) GroceryItems() []paprika.GroceryItem {
+	var result []paprika.GroceryItem
+	s.list(groceries, &result)
+	return result
+}
+
+// PutMeal stores a meal planner entry directly, bypassing the API
+func (s *Server) PutMeal(meal paprika.Meal) {
+	s.put(meals, meal.UID, meal)
+}
+
+// Meals returns all stored meal planner entries, sorted by UID
+func (s *Server) Meals() paprika.Meals {
+	var result paprika.Meals
+	s.list(meals, &result)
+	return result
+}
+
+// PutPantryItem stores a pantry item directly, bypassing the API
+func (s *Server) PutPantryItem(item paprika.PantryItem) {
+	s.put(pantry, item.UID, item)
+}
+
+// PantryItems returns all stored pantry items, sorted by UID
+func (s *Server) PantryItems() paprika.PantryItems {
+	var result paprika.PantryItems
+	s.list(pantry, &result)
+	return result
+}
+
+// Notifications returns how many times /v2/sync/notify was called
+func (s *Server) Notifications() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.notifications
+}
+
+// Requests returns how many requests with the given method and path prefix the server received,
+// e.g. to check that a client doesn't download the recipe list more often than it needs to
+func (s *Server) Requests(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, r := range s.requests {
+		if strings.HasPrefix(r, method+" "+path) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) injectFailures(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f := s.nextFailure(r); f != nil {
+			if f.ErrorMessage != "" || f.ErrorCode != 0 {
+				writeJSON(w, f.Status, errorBody(f.ErrorCode, f.ErrorMessage))
+				return
+			}
+			http.Error(w, http.StatusText(f.Status), f.Status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) nextFailure(r *http.Request) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.failures {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		failure := *f
+		f.Count--
+		if f.Count <= 0 {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+		}
+		return &failure
+	}
+	return nil
+}
+
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			writeJSON(w, http.StatusUnauthorized, errorBody(0, "Unrecognized client"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorBody(0, err.Error()))
+		return
+	}
+
+	if r.PostForm.Get("email") != s.username || r.PostForm.Get("password") != s.password {
+		writeJSON(w, http.StatusUnauthorized, errorBody(0, "Invalid email or password"))
+		return
+	}
+
+	writeResult(w, map[string]string{"token": Token})
+}
+
+type recipeListEntry struct {
+	UID  string `json:"uid"`
+	Hash string `json:"hash"`
+}
+
+func (s *Server) handleListRecipes(w http.ResponseWriter, r *http.Request) {
+	recipes := s.Recipes()
+	entries := make([]recipeListEntry, 0, len(recipes))
+	for _, recipe := range recipes {
+		entries = append(entries, recipeListEntry{UID: recipe.UID, Hash: recipe.Hash})
+	}
+	writeResult(w, entries)
+}
+
+func (s *Server) handleGetRecipe(w http.ResponseWriter, r *http.Request) {
+	recipe, ok := s.Recipe(r.PathValue("uid"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorBody(0, "Recipe not found"))
+		return
+	}
+	writeResult(w, recipe)
+}
+
+func (s *Server) handleSaveRecipe(w http.ResponseWriter, r *http.Request) {
+	var recipe paprika.Recipe
+	if err := readGzipData(r, &recipe); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorBody(0, err.Error()))
+		return
+	}
+
+	if recipe.UID != r.PathValue("uid") {
+		writeJSON(w, http.StatusOK, errorBody(0, "UID mismatch"))
+		return
+	}
+
+	s.PutRecipe(recipe)
+	writeResult(w, true)
+}
+
+func (s *Server) handleListCollection(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var result []json.RawMessage
+		s.list(name, &result)
+		writeResult(w, result)
+	}
+}
+
+func (s *Server) handleSaveCollection(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var items []json.RawMessage
+		if err := readGzipData(r, &items); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorBody(0, err.Error()))
+			return
+		}
+
+		for _, raw := range items {
+			var item collectionItem
+			if err := json.Unmarshal(raw, &item); err != nil || item.UID == "" {
+				writeJSON(w, http.StatusOK, errorBody(0, "invalid item"))
+				return
+			}
+			if item.Deleted {
+				s.remove(name, item.UID)
+				continue
+			}
+			s.put(name, item.UID, raw)
+		}
+		writeResult(w, true)
+	}
+}
+
+func (s *Server) handleNotify(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.notifications++
+	s.mu.Unlock()
+	writeResult(w, true)
+}
+
+// readGzipData decodes the gzipped JSON "data" form file the Paprika apps use for uploads
+func readGzipData(r *http.Request, v interface{}) error {
+	file, _, err := r.FormFile("data")
+	if err != nil {
+		return fmt.Errorf("missing data: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("data is not gzipped: %w", err)
+	}
+	defer reader.Close()
+
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+func errorBody(code int, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"result": result})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func hashOf(v interface{}) string {
+	raw, _ := json.Marshal(v)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
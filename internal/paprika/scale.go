@@ -0,0 +1,233 @@
+package paprika
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// metricUnits are written as decimals rather than fractions
+var metricUnits = map[string]bool{"g": true, "kg": true, "mg": true, "ml": true, "cl": true, "dl": true, "l": true}
+
+// abbreviatedUnits aren't pluralized
+var abbreviatedUnits = map[string]bool{"tsp": true, "tbsp": true, "fl oz": true, "oz": true, "lb": true}
+
+// servingsPattern finds the number of servings in text like "Serves 4-6"
+var servingsPattern = regexp.MustCompile(`(?:` + number + `)(?:\s*[-–—]\s*(?:` + number + `))?`)
+
+// Scale multiplies the quantity by factor
+func (q Quantity) Scale(factor float64) Quantity {
+	return Quantity{Value: q.Value * factor, Max: q.Max * factor}
+}
+
+// String renders the quantity with the fractions cooks measure with, e.g. "1 1/2" or "2-3"
+func (q Quantity) String() string {
+	return q.format(formatFraction)
+}
+
+func (q Quantity) format(formatAmount func(float64) string) string {
+	if q.IsRange() {
+		return formatAmount(q.Value) + "-" + formatAmount(q.Max)
+	}
+	return formatAmount(q.Value)
+}
+
+// formatFraction renders an amount as a whole number and a fraction in halves, thirds, quarters or eighths
+// if it is close to one, and as a decimal otherwise
+func formatFraction(v float64) string {
+	whole := math.Floor(v)
+	rest := v - whole
+
+	for _, denominator := range []float64{2, 3, 4, 8} {
+		numerator := math.Round(rest * denominator)
+		if math.Abs(rest-numerator/denominator) > 0.02 {
+			continue
+		}
+
+		switch {
+		case numerator == 0 && whole > 0:
+			return strconv.FormatFloat(whole, 'f', -1, 64)
+		case numerator == 0:
+			// too small for a fraction, e.g. a pinch scaled down
+			return formatDecimal(v)
+		case numerator == denominator:
+			return strconv.FormatFloat(whole+1, 'f', -1, 64)
+		case whole == 0:
+			return fmt.Sprintf("%d/%d", int(numerator), int(denominator))
+		default:
+			return fmt.Sprintf("%d %d/%d", int(whole), int(numerator), int(denominator))
+		}
+	}
+
+	return formatDecimal(v)
+}
+
+// formatDecimal renders an amount with at most two decimals, or two significant digits if it is smaller
+func formatDecimal(v float64) string {
+	decimals := 2
+	if v > 0 && v < 0.1 {
+		decimals = 1 - int(math.Floor(math.Log10(v)))
+	}
+	scale := math.Pow(10, float64(decimals))
+	return strconv.FormatFloat(math.Round(v*scale)/scale, 'f', -1, 64)
+}
+
+// String renders the ingredient as a line of a recipe, e.g. "1 1/2 cups flour, sifted".
+// Quantities of metric units are written as decimals, others as fractions.
+func (i Ingredient) String() string {
+	if i.Header {
+		return i.Name + ":"
+	}
+
+	var parts []string
+	plural := false
+	if i.Quantity != nil {
+		if metricUnits[i.Unit] {
+			parts = append(parts, i.Quantity.format(formatDecimal))
+		} else {
+			parts = append(parts, i.Quantity.String())
+		}
+		plural = i.Quantity.Value > 1 || i.Quantity.IsRange()
+	}
+	if i.Unit != "" {
+		parts = append(parts, unitName(i.Unit, plural))
+	}
+	if i.Name != "" {
+		parts = append(parts, i.Name)
+	}
+
+	line := strings.Join(parts, " ")
+	if i.Note != "" {
+		line += ", " + i.Note
+	}
+	if i.Optional {
+		line += " (optional)"
+	}
+	return line
+}
+
+func unitName(unit string, plural bool) string {
+	switch {
+	case !plural || abbreviatedUnits[unit] || metricUnits[unit]:
+		return unit
+	case strings.HasSuffix(unit, "ch"), strings.HasSuffix(unit, "sh"):
+		return unit + "es"
+	default:
+		return unit + "s"
+	}
+}
+
+// Scaled returns a copy of the recipe with its ingredient quantities and servings multiplied by factor.
+// Lines without a quantity are kept as they are.
+func (r *Recipe) Scaled(factor float64) (*Recipe, error) {
+	if factor <= 0 || math.IsInf(factor, 0) || math.IsNaN(factor) {
+		return nil, errors.New("scale factor must be positive")
+	}
+
+	scaled := *r
+	scaled.Categories = append([]string(nil), r.Categories...)
+	// Scale is how much the app scales the recipe when showing it; the copy's ingredients are already scaled
+	scaled.Scale = ""
+
+	lines := strings.Split(r.Ingredients, "\n")
+	for n, line := range lines {
+		ingredient := ParseIngredient(line)
+		if ingredient.Header || ingredient.Quantity == nil {
+			continue
+		}
+		lines[n] = scaleLine(line, ingredient, factor)
+	}
+	scaled.Ingredients = strings.Join(lines, "\n")
+
+	if servings, ok := r.ServingsQuantity(); ok {
+		text := normalizeFractions(r.Servings)
+		loc := servingsPattern.FindStringIndex(text)
+		scaled.Servings = text[:loc[0]] + servings.Scale(factor).String() + text[loc[1]:]
+	}
+
+	return &scaled, nil
+}
+
+// scaleLine replaces the quantity at the start of an ingredient line with the scaled one. The rest of
+// the line is kept as it was written, apart from a unit word following the quantity, e.g. "cup" in
+// "1 cup flour", which is made singular or plural to match.
+func scaleLine(line string, ingredient Ingredient, factor float64) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+	rest := trimmed[quantityEnd(trimmed):]
+
+	quantity := ingredient.Quantity.Scale(factor)
+	amount := quantity.String()
+	if metricUnits[ingredient.Unit] {
+		amount = quantity.format(formatDecimal)
+	}
+
+	if ingredient.Unit != "" && !abbreviatedUnits[ingredient.Unit] && !metricUnits[ingredient.Unit] {
+		if m := unitWord.FindStringSubmatchIndex(strings.TrimLeft(rest, " ")); m != nil {
+			offset := len(rest) - len(strings.TrimLeft(rest, " "))
+			word := rest[offset+m[2] : offset+m[3]]
+			if word == ingredient.Unit || word == unitName(ingredient.Unit, true) {
+				plural := quantity.Value > 1 || quantity.IsRange()
+				rest = rest[:offset+m[2]] + unitName(ingredient.Unit, plural) + rest[offset+m[3]:]
+			}
+		}
+	}
+
+	return indent + amount + rest
+}
+
+// quantityEnd returns the length of the quantity at the start of a line, as parsed by parseQuantity.
+// Unicode fractions like "½" count as part of the quantity.
+func quantityEnd(line string) int {
+	text := normalizeFractions(line)
+	end := 0
+	if m := quantityPattern.FindStringSubmatch(text); m != nil {
+		end = len(m[0])
+		// "2 or more" matches a separator without a second number
+		if m[2] == "" {
+			end = len(m[1])
+		}
+	} else if article, _, ok := strings.Cut(text, " "); ok {
+		// "a" or "an", as in "a pinch of salt"
+		end = len(article)
+	}
+
+	// find where the quantity ends in the line before its fractions were normalized
+	for i := range line {
+		if len(normalizeFractions(line[:i])) >= end {
+			return i
+		}
+	}
+	return len(line)
+}
+
+// ScaledToServings returns a copy of the recipe scaled to make the given number of servings.
+// For recipes with a range of servings like "4-6", the lower bound is used.
+func (r *Recipe) ScaledToServings(servings float64) (*Recipe, error) {
+	current, ok := r.ServingsQuantity()
+	if !ok || current.Value == 0 {
+		return nil, fmt.Errorf("%s doesn't say how many servings it makes", r.Name)
+	}
+	if servings <= 0 {
+		return nil, errors.New("servings must be positive")
+	}
+
+	return r.Scaled(servings / current.Value)
+}
+
+// ServingsQuantity returns the number of servings the recipe makes, parsed from text like "Serves 4"
+func (r *Recipe) ServingsQuantity() (Quantity, bool) {
+	match := servingsPattern.FindString(normalizeFractions(r.Servings))
+	if match == "" {
+		return Quantity{}, false
+	}
+
+	q, _ := parseQuantity(match)
+	if q == nil {
+		return Quantity{}, false
+	}
+	return *q, true
+}
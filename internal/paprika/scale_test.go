@@ -0,0 +1,73 @@
+package paprika_test
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantityString(t *testing.T) {
+	tests := map[float64]string{
+		0.5:   "1/2",
+		1.5:   "1 1/2",
+		0.333: "1/3",
+		2.667: "2 2/3",
+		0.375: "3/8",
+		3:     "3",
+		1.99:  "2",
+		0.2:   "0.2",
+		1.1:   "1.1",
+		1.234: "1 1/4",
+		// amounts too small for a fraction keep their leading digits
+		0.0125: "0.013",
+		0.015:  "0.015",
+		0.004:  "0.004",
+	}
+
+	for value, want := range tests {
+		assert.Equal(t, want, paprika.Quantity{Value: value}.String(), "%v", value)
+	}
+	assert.Equal(t, "1-1 1/2", paprika.Quantity{Value: 1, Max: 1.5}.String())
+}
+
+func TestRecipeScaled(t *testing.T) {
+	recipe := paprika.Recipe{
+		Name:        "Pancakes",
+		Servings:    "Serves 4",
+		Scale:       "1/1",
+		Ingredients: "1 1/2 cups flour\n1 egg\n2-3 tbsp sugar\n250 g milk\n1 clove garlic, minced\nCrust:\nsalt to taste",
+	}
+
+	doubled, err := recipe.Scaled(2)
+	require.NoError(t, err)
+	assert.Equal(t, "3 cups flour\n2 egg\n4-6 tbsp sugar\n500 g milk\n2 cloves garlic, minced\nCrust:\nsalt to taste", doubled.Ingredients)
+	assert.Equal(t, "Serves 8", doubled.Servings)
+	assert.Empty(t, doubled.Scale)
+	// the original is untouched
+	assert.Equal(t, "Serves 4", recipe.Servings)
+
+	halved, err := recipe.ScaledToServings(2)
+	require.NoError(t, err)
+	assert.Equal(t, "3/4 cup flour\n1/2 egg\n1-1 1/2 tbsp sugar\n125 g milk\n1/2 clove garlic, minced\nCrust:\nsalt to taste", halved.Ingredients)
+	assert.Equal(t, "Serves 2", halved.Servings)
+
+	// only the quantity is rewritten, and the unit word made to agree with it
+	verbatim := paprika.Recipe{Ingredients: "1 (14 oz) can tomatoes\n½ cup milk, warm (not hot)\n  2 Tbsp. olive oil\na pinch of salt\n2 or more eggs"}
+	tripled, err := verbatim.Scaled(3)
+	require.NoError(t, err)
+	assert.Equal(t, "3 (14 oz) can tomatoes\n1 1/2 cups milk, warm (not hot)\n  6 Tbsp. olive oil\n3 pinches of salt\n6 or more eggs", tripled.Ingredients)
+
+	small := paprika.Recipe{Ingredients: "1/8 tsp salt\n1 g saffron"}
+	tiny, err := small.Scaled(0.1)
+	require.NoError(t, err)
+	assert.Equal(t, "0.013 tsp salt\n0.1 g saffron", tiny.Ingredients)
+
+	_, err = recipe.Scaled(0)
+	assert.Error(t, err)
+
+	recipe.Servings = ""
+	_, err = recipe.ScaledToServings(2)
+	assert.Error(t, err)
+}
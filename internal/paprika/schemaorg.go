@@ -0,0 +1,462 @@
+package paprika
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaRecipe is a recipe in the schema.org vocabulary, as embedded in recipe web pages as JSON-LD
+type SchemaRecipe struct {
+	Context            string            `json:"@context"`
+	Type               string            `json:"@type"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description,omitempty"`
+	Image              []string          `json:"image,omitempty"`
+	URL                string            `json:"url,omitempty"`
+	Publisher          *SchemaThing      `json:"publisher,omitempty"`
+	DateCreated        string            `json:"dateCreated,omitempty"`
+	RecipeYield        string            `json:"recipeYield,omitempty"`
+	PrepTime           string            `json:"prepTime,omitempty"`
+	CookTime           string            `json:"cookTime,omitempty"`
+	TotalTime          string            `json:"totalTime,omitempty"`
+	RecipeCategory     []string          `json:"recipeCategory,omitempty"`
+	RecipeIngredient   []string          `json:"recipeIngredient,omitempty"`
+	RecipeInstructions []HowTo           `json:"recipeInstructions,omitempty"`
+	Nutrition          map[string]string `json:"nutrition,omitempty"`
+}
+
+// SchemaThing is a named schema.org entity, e.g. an Organization
+type SchemaThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// HowTo is a HowToStep, or a HowToSection grouping steps
+type HowTo struct {
+	Type            string  `json:"@type"`
+	Name            string  `json:"name,omitempty"`
+	Text            string  `json:"text,omitempty"`
+	ItemListElement []HowTo `json:"itemListElement,omitempty"`
+}
+
+// nutritionLabels maps schema.org NutritionInformation properties to the labels used in
+// Paprika's nutritional info, e.g. "Protein: 12 g", along with other labels people use
+var nutritionLabels = []struct {
+	property string
+	label    string
+	aliases  []string
+}{
+	{"servingSize", "Serving size", nil},
+	{"calories", "Calories", []string{"energy", "kcal"}},
+	{"fatContent", "Fat", []string{"total fat"}},
+	{"saturatedFatContent", "Saturated fat", []string{"saturates"}},
+	{"unsaturatedFatContent", "Unsaturated fat", nil},
+	{"transFatContent", "Trans fat", nil},
+	{"cholesterolContent", "Cholesterol", nil},
+	{"sodiumContent", "Sodium", nil},
+	{"carbohydrateContent", "Carbohydrates", []string{"carbs", "carbohydrate", "total carbohydrates"}},
+	{"fiberContent", "Fiber", []string{"fibre", "dietary fiber"}},
+	{"sugarContent", "Sugar", []string{"sugars"}},
+	{"proteinContent", "Protein", nil},
+}
+
+// ISODuration renders a duration in ISO 8601, e.g. "PT1H15M", rounded up to the minute
+func ISODuration(d time.Duration) string {
+	minutes := int(math.Ceil(d.Minutes()))
+	s := "PT"
+	if minutes >= 60 {
+		s += fmt.Sprintf("%dH", minutes/60)
+	}
+	if minutes%60 != 0 || minutes < 60 {
+		s += fmt.Sprintf("%dM", minutes%60)
+	}
+	return s
+}
+
+// SchemaOrg converts the recipe to a schema.org Recipe. categories are used to name the recipe's categories.
+func (r *Recipe) SchemaOrg(categories Categories) SchemaRecipe {
+	s := SchemaRecipe{
+		Context:        "https://schema.org",
+		Type:           "Recipe",
+		Name:           r.Name,
+		Description:    r.Description,
+		URL:            r.SourceURL,
+		RecipeYield:    r.Servings,
+		RecipeCategory: categories.Names(r.Categories),
+	}
+	if len(s.RecipeCategory) == 0 {
+		s.RecipeCategory = nil
+	}
+	for _, image := range []string{r.ImageURL, r.PhotoURL} {
+		if image != "" {
+			s.Image = append(s.Image, image)
+		}
+	}
+	if r.Source != "" {
+		s.Publisher = &SchemaThing{Type: "Organization", Name: r.Source}
+	}
+	if created, ok := r.CreatedAt(); ok {
+		s.DateCreated = created.Format("2006-01-02T15:04:05")
+	}
+
+	if d, ok := r.PrepDuration(); ok && d > 0 {
+		s.PrepTime = ISODuration(d)
+	}
+	if d, ok := r.CookDuration(); ok && d > 0 {
+		s.CookTime = ISODuration(d)
+	}
+	if d, ok := r.TotalDuration(); ok && d > 0 {
+		s.TotalTime = ISODuration(d)
+	}
+
+	for _, ingredient := range r.ParsedIngredients() {
+		// schema.org has no ingredient sections
+		if !ingredient.Header && strings.TrimSpace(ingredient.Raw) != "" {
+			s.RecipeIngredient = append(s.RecipeIngredient, strings.TrimSpace(ingredient.Raw))
+		}
+	}
+	s.RecipeInstructions = schemaInstructions(r.Directions)
+	s.Nutrition = schemaNutrition(r.NutritionalInfo)
+	// the rating is the user's own, not a public aggregateRating, so it isn't published
+	return s
+}
+
+// schemaInstructions turns directions into steps, grouped in sections by header lines like "For the sauce:"
+func schemaInstructions(directions string) []HowTo {
+	var steps []HowTo
+	var section *HowTo
+	for _, line := range strings.Split(directions, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case isHeader(line):
+			steps = append(steps, HowTo{Type: "HowToSection", Name: strings.TrimSuffix(line, ":")})
+			section = &steps[len(steps)-1]
+		case section != nil:
+			section.ItemListElement = append(section.ItemListElement, HowTo{Type: "HowToStep", Text: line})
+		default:
+			steps = append(steps, HowTo{Type: "HowToStep", Text: line})
+		}
+	}
+	return steps
+}
+
+// schemaNutrition picks the nutrients schema.org knows out of lines like "Protein: 12 g"
+func schemaNutrition(info string) map[string]string {
+	nutrition := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		label, value, ok := strings.Cut(line, ":")
+		label, value = strings.ToLower(strings.TrimSpace(label)), strings.TrimSpace(value)
+		if !ok || value == "" {
+			continue
+		}
+		for _, n := range nutritionLabels {
+			if label == strings.ToLower(n.label) || strings.EqualFold(label, n.property) || containsFold(n.aliases, label) {
+				nutrition[n.property] = value
+				break
+			}
+		}
+	}
+	if len(nutrition) == 0 {
+		return nil
+	}
+	nutrition["@type"] = "NutritionInformation"
+	return nutrition
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSONLD renders the recipe as indented schema.org JSON-LD
+func (r *Recipe) MarshalJSONLD(categories Categories) ([]byte, error) {
+	return json.MarshalIndent(r.SchemaOrg(categories), "", "  ")
+}
+
+// jsonLDScript finds the JSON-LD blocks of an HTML page
+var jsonLDScript = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+
+// ErrNoSchemaRecipe is returned when a page has no schema.org recipe
+var ErrNoSchemaRecipe = errors.New("no schema.org recipe found")
+
+// RecipeFromHTML extracts the schema.org recipe embedded in a web page as JSON-LD, like the
+// Paprika app's browser does. pageURL is the address of the page, if known; it is used as the
+// source and to resolve relative image URLs. The recipe's Categories holds category names
+// rather than UIDs.
+func RecipeFromHTML(page string, pageURL string) (*Recipe, error) {
+	for _, m := range jsonLDScript.FindAllStringSubmatch(page, -1) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(m[1])), &data); err != nil {
+			// sites sometimes HTML-escape their JSON-LD
+			if err := json.Unmarshal([]byte(html.UnescapeString(m[1])), &data); err != nil {
+				continue
+			}
+		}
+		if node := findSchemaRecipe(data); node != nil {
+			return RecipeFromSchemaOrg(node, pageURL), nil
+		}
+	}
+	return nil, ErrNoSchemaRecipe
+}
+
+// findSchemaRecipe looks for a Recipe node in JSON-LD, including in @graph and mainEntity
+func findSchemaRecipe(data interface{}) map[string]interface{} {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if node := findSchemaRecipe(item); node != nil {
+				return node
+			}
+		}
+	case map[string]interface{}:
+		if isSchemaType(v["@type"], "Recipe") {
+			return v
+		}
+		for _, key := range []string{"@graph", "mainEntity", "mainEntityOfPage"} {
+			if node := findSchemaRecipe(v[key]); node != nil {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+// isSchemaType reports whether an @type, which may be a list and may be written
+// as "Recipe", "schema:Recipe" or "https://schema.org/Recipe", includes the given type
+func isSchemaType(v interface{}, want string) bool {
+	for _, t := range schemaTexts(v) {
+		if i := strings.LastIndexAny(t, "/:"); i >= 0 {
+			t = t[i+1:]
+		}
+		if strings.EqualFold(t, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecipeFromSchemaOrg converts a decoded schema.org Recipe node to a recipe. The recipe's Categories
+// holds category names rather than UIDs.
+func RecipeFromSchemaOrg(node map[string]interface{}, pageURL string) *Recipe {
+	r := &Recipe{
+		Name:        schemaText(node["name"]),
+		Description: schemaText(node["description"]),
+		Servings:    schemaText(node["recipeYield"]),
+		PrepTime:    schemaDuration(node["prepTime"]),
+		CookTime:    schemaDuration(node["cookTime"]),
+		TotalTime:   schemaDuration(node["totalTime"]),
+		SourceURL:   schemaText(node["url"]),
+	}
+	if r.SourceURL == "" {
+		r.SourceURL = pageURL
+	}
+
+	for _, key := range []string{"publisher", "author"} {
+		if r.Source = schemaText(node[key]); r.Source != "" {
+			break
+		}
+	}
+	if u, err := url.Parse(r.SourceURL); r.Source == "" && err == nil {
+		r.Source = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+
+	if images := schemaURLs(node["image"]); len(images) > 0 {
+		r.ImageURL = resolveURL(pageURL, images[0])
+	}
+
+	var ingredients []string
+	for _, ingredient := range schemaTexts(node["recipeIngredient"]) {
+		if ingredient != "" {
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+	if len(ingredients) == 0 {
+		// older pages use the deprecated ingredients property
+		ingredients = schemaTexts(node["ingredients"])
+	}
+	r.Ingredients = strings.Join(ingredients, "\n")
+	r.Directions = strings.Join(directionLines(node["recipeInstructions"]), "\n")
+	r.NutritionalInfo = nutritionText(node["nutrition"])
+	// a page's aggregateRating is other people's opinion, so the user's own rating is left unset
+
+	seen := make(map[string]bool)
+	for _, key := range []string{"recipeCategory", "recipeCuisine"} {
+		for _, value := range schemaTexts(node[key]) {
+			for _, name := range strings.Split(value, ",") {
+				name = strings.TrimSpace(name)
+				if name != "" && !seen[strings.ToLower(name)] {
+					seen[strings.ToLower(name)] = true
+					r.Categories = append(r.Categories, name)
+				}
+			}
+		}
+	}
+
+	return r
+}
+
+var (
+	htmlTag    = regexp.MustCompile(`<[^>]*>`)
+	lineBreak  = regexp.MustCompile(`(?i)<br\s*/?>|</p>`)
+	whitespace = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// cleanText strips the markup and entities pages leave in their JSON-LD text
+func cleanText(s string) string {
+	s = lineBreak.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+	lines := strings.Split(whitespace.ReplaceAllString(s, " "), "\n")
+	for n := range lines {
+		lines[n] = strings.TrimSpace(lines[n])
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// schemaText reads a text property, which may be given as a string, a number, a list or a named object
+func schemaText(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return cleanText(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		for _, item := range v {
+			if s := schemaText(item); s != "" {
+				return s
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"name", "text", "@value"} {
+			if s := schemaText(v[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// schemaTexts reads a property that may hold one value or a list of them
+func schemaTexts(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		items = []interface{}{v}
+	}
+
+	var texts []string
+	for _, item := range items {
+		if s := schemaText(item); s != "" {
+			texts = append(texts, s)
+		}
+	}
+	return texts
+}
+
+// schemaURLs reads an image property: URLs, ImageObjects, or a list of either
+func schemaURLs(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{strings.TrimSpace(v)}
+	case []interface{}:
+		var urls []string
+		for _, item := range v {
+			urls = append(urls, schemaURLs(item)...)
+		}
+		return urls
+	case map[string]interface{}:
+		for _, key := range []string{"url", "contentUrl", "@id"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				return []string{strings.TrimSpace(s)}
+			}
+		}
+	}
+	return nil
+}
+
+func resolveURL(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil || base == "" {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// schemaDuration renders an ISO 8601 duration the way Paprika shows times, keeping anything it can't parse
+func schemaDuration(v interface{}) string {
+	s := schemaText(v)
+	if d, ok := ParseDuration(s); ok && strings.HasPrefix(strings.ToUpper(s), "P") {
+		if d == 0 {
+			return ""
+		}
+		return FormatDuration(d)
+	}
+	return s
+}
+
+// directionLines flattens recipeInstructions, which may be text, a list of strings or HowToSteps,
+// or HowToSections of steps. Section names become header lines like "For the sauce:".
+func directionLines(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		var lines []string
+		for _, line := range strings.Split(cleanText(v), "\n") {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	case []interface{}:
+		var lines []string
+		for _, item := range v {
+			lines = append(lines, directionLines(item)...)
+		}
+		return lines
+	case map[string]interface{}:
+		if steps, ok := v["itemListElement"]; ok {
+			var lines []string
+			if name := schemaText(v["name"]); name != "" && isSchemaType(v["@type"], "HowToSection") {
+				lines = append(lines, strings.TrimSuffix(name, ":")+":")
+			}
+			return append(lines, directionLines(steps)...)
+		}
+		text := schemaText(v["text"])
+		if text == "" {
+			text = schemaText(v["name"])
+		}
+		return directionLines(text)
+	}
+	return nil
+}
+
+// nutritionText renders NutritionInformation as lines like "Protein: 12 g"
+func nutritionText(v interface{}) string {
+	node, ok := v.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	var lines []string
+	for _, n := range nutritionLabels {
+		if value := schemaText(node[n.property]); value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", n.label, value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
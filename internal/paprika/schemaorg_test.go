@@ -0,0 +1,133 @@
+package paprika_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const recipePage = `<html><head>
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "WebSite", "name": "Example"}</script>
+<script type="application/ld+json">
+{
+  "@context": "https://schema.org",
+  "@graph": [
+    {"@type": "WebPage", "url": "https://www.example.com/soup"},
+    {
+      "@type": ["Recipe", "NewsArticle"],
+      "name": "Tomato &amp; Basil Soup",
+      "description": "<p>A quick soup.</p>",
+      "image": [{"@type": "ImageObject", "url": "/images/soup.jpg"}],
+      "author": {"@type": "Person", "name": "Jo Cook"},
+      "recipeYield": ["4", "4 bowls"],
+      "prepTime": "PT15M",
+      "cookTime": "PT1H",
+      "totalTime": "PT1H15M",
+      "recipeCategory": "Dinner, Soup",
+      "recipeCuisine": ["Italian", "soup"],
+      "recipeIngredient": ["2 tbsp olive oil", "1 kg tomatoes", " "],
+      "recipeInstructions": [
+        {"@type": "HowToSection", "name": "For the soup", "itemListElement": [
+          {"@type": "HowToStep", "text": "Fry the tomatoes."},
+          {"@type": "HowToStep", "name": "Simmer for 20 minutes."}
+        ]},
+        "Blend until smooth."
+      ],
+      "nutrition": {"@type": "NutritionInformation", "calories": "120 kcal", "proteinContent": "3 g"},
+      "aggregateRating": {"@type": "AggregateRating", "ratingValue": "4.6", "ratingCount": "31"}
+    }
+  ]
+}
+</script></head><body></body></html>`
+
+func TestRecipeFromHTML(t *testing.T) {
+	recipe, err := paprika.RecipeFromHTML(recipePage, "https://www.example.com/soup")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Tomato & Basil Soup", recipe.Name)
+	assert.Equal(t, "A quick soup.", recipe.Description)
+	assert.Equal(t, "https://www.example.com/images/soup.jpg", recipe.ImageURL)
+	assert.Equal(t, "Jo Cook", recipe.Source)
+	assert.Equal(t, "https://www.example.com/soup", recipe.SourceURL)
+	assert.Equal(t, "4", recipe.Servings)
+	assert.Equal(t, "15 mins", recipe.PrepTime)
+	assert.Equal(t, "1 hr", recipe.CookTime)
+	assert.Equal(t, "1 hr 15 mins", recipe.TotalTime)
+	assert.Equal(t, []string{"Dinner", "Soup", "Italian"}, recipe.Categories)
+	assert.Equal(t, "2 tbsp olive oil\n1 kg tomatoes", recipe.Ingredients)
+	assert.Equal(t, "For the soup:\nFry the tomatoes.\nSimmer for 20 minutes.\nBlend until smooth.", recipe.Directions)
+	assert.Equal(t, "Calories: 120 kcal\nProtein: 3 g", recipe.NutritionalInfo)
+	assert.Zero(t, recipe.Rating, "the page's aggregate rating isn't the user's own")
+
+	_, err = paprika.RecipeFromHTML("<html><body>No recipe here</body></html>", "")
+	assert.ErrorIs(t, err, paprika.ErrNoSchemaRecipe)
+}
+
+func TestRecipeFromHTMLWithPlainInstructions(t *testing.T) {
+	page := `<script type='application/ld+json'>{"@type": "http://schema.org/Recipe", "name": "Toast",
+		"recipeInstructions": "Toast the bread.<br/>Butter it.", "url": "https://toast.example.org/toast"}</script>`
+
+	recipe, err := paprika.RecipeFromHTML(page, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Toast the bread.\nButter it.", recipe.Directions)
+	assert.Equal(t, "toast.example.org", recipe.Source)
+}
+
+func TestRecipeSchemaOrg(t *testing.T) {
+	recipe := paprika.Recipe{
+		Name:            "Soup",
+		Ingredients:     "Soup:\n1 onion\n\n2 cups stock",
+		Directions:      "Chop the onion.\nFor the soup:\nSimmer.\n\nServe.",
+		Servings:        "4 servings",
+		PrepTime:        "10 mins",
+		CookTime:        "1 hr 5 mins",
+		Source:          "Grandma",
+		SourceURL:       "https://example.com/soup",
+		ImageURL:        "https://example.com/soup.jpg",
+		NutritionalInfo: "Calories: 200\nCarbs: 20 g\nVibes: good",
+		Rating:          4,
+		Categories:      []string{"DINNER"},
+		Created:         "2024-03-01 12:00:00",
+	}
+	s := recipe.SchemaOrg(paprika.Categories{{UID: "DINNER", Name: "Dinner"}})
+
+	assert.Equal(t, "Recipe", s.Type)
+	assert.Equal(t, []string{"1 onion", "2 cups stock"}, s.RecipeIngredient)
+	assert.Equal(t, []paprika.HowTo{
+		{Type: "HowToStep", Text: "Chop the onion."},
+		{Type: "HowToSection", Name: "For the soup", ItemListElement: []paprika.HowTo{
+			{Type: "HowToStep", Text: "Simmer."},
+			{Type: "HowToStep", Text: "Serve."},
+		}},
+	}, s.RecipeInstructions)
+	assert.Equal(t, "PT10M", s.PrepTime)
+	assert.Equal(t, "PT1H5M", s.CookTime)
+	assert.Equal(t, "PT1H15M", s.TotalTime)
+	assert.Equal(t, []string{"Dinner"}, s.RecipeCategory)
+	assert.Equal(t, map[string]string{"@type": "NutritionInformation", "calories": "200", "carbohydrateContent": "20 g"}, s.Nutrition)
+	assert.Equal(t, "2024-03-01T12:00:00", s.DateCreated)
+
+	// the JSON-LD reads back into the same recipe
+	data, err := recipe.MarshalJSONLD(paprika.Categories{{UID: "DINNER", Name: "Dinner"}})
+	require.NoError(t, err)
+	var node map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &node))
+	back := paprika.RecipeFromSchemaOrg(node, "")
+	assert.Equal(t, "1 onion\n2 cups stock", back.Ingredients)
+	assert.Equal(t, "Chop the onion.\nFor the soup:\nSimmer.\nServe.", back.Directions)
+	assert.Equal(t, "Grandma", back.Source)
+	assert.Equal(t, "1 hr 5 mins", back.CookTime)
+	assert.Equal(t, []string{"Dinner"}, back.Categories)
+	assert.NotContains(t, string(data), "aggregateRating")
+	assert.Zero(t, back.Rating)
+}
+
+func TestISODuration(t *testing.T) {
+	assert.Equal(t, "PT45M", paprika.ISODuration(45*time.Minute))
+	assert.Equal(t, "PT2H", paprika.ISODuration(2*time.Hour))
+	assert.Equal(t, "PT1H1M", paprika.ISODuration(time.Hour+30*time.Second))
+}
@@ -0,0 +1,266 @@
+package paprika
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Amount is a quantity of a shopping list item in a unit
+type Amount struct {
+	Quantity Quantity `json:"quantity"`
+	Unit     string   `json:"unit,omitempty"`
+}
+
+// ShoppingItem is an ingredient to buy, merged across the recipes that need it
+type ShoppingItem struct {
+	Name string `json:"name"`
+	// Amounts holds the total per kind of unit, e.g. grams of flour; amounts in units that can't
+	// be converted into each other, like cloves and teaspoons of garlic, are listed separately.
+	// It is empty if no recipe says how much is needed.
+	Amounts []Amount `json:"amounts,omitempty"`
+	Aisle   string   `json:"aisle"`
+	// Recipes are the names of the recipes that need the item
+	Recipes []string `json:"recipes"`
+	// Optional is true if every recipe lists the item as optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// String renders the item as a grocery list entry, e.g. "250 g flour" or "2 cloves + 1 tsp garlic"
+func (i ShoppingItem) String() string {
+	parts := make([]string, 0, len(i.Amounts))
+	for _, a := range i.Amounts {
+		parts = append(parts, Ingredient{Quantity: &a.Quantity, Unit: a.Unit}.String())
+	}
+
+	line := i.Name
+	if len(parts) > 0 {
+		line = strings.Join(parts, " + ") + " " + i.Name
+	}
+	if i.Optional {
+		line += " (optional)"
+	}
+	return line
+}
+
+// shoppingEntry accumulates an item: amounts of convertible units are kept in grams and millilitres
+type shoppingEntry struct {
+	item   ShoppingItem
+	order  []string
+	grams  *Quantity
+	ml     *Quantity
+	counts map[string]*Quantity
+	// units records which unit systems the amounts were given in, to render them the same way
+	units map[UnitSystem]int
+}
+
+// addQuantity adds q to a running total, which is created if needed
+func addQuantity(total **Quantity, q Quantity) {
+	if *total == nil {
+		*total = &Quantity{}
+	}
+	upper := q.Max
+	if !q.IsRange() {
+		upper = q.Value
+	}
+	// keep Max in step with Value so that adding a range to a single amount gives a range
+	if !(*total).IsRange() {
+		(*total).Max = (*total).Value
+	}
+	(*total).Value += q.Value
+	(*total).Max += upper
+}
+
+// NewShoppingList merges the ingredients of recipes into a shopping list sorted by aisle and name.
+// Ingredients are merged by name, converting between units and, for common ingredients like flour,
+// between volume and weight. Amounts are rendered in the given unit system, or in the system most
+// of the recipes used if system is empty.
+func NewShoppingList(system UnitSystem, recipes ...*Recipe) []ShoppingItem {
+	entries := make(map[string]*shoppingEntry)
+	var keys []string
+
+	for _, recipe := range recipes {
+		for _, ingredient := range recipe.ParsedIngredients() {
+			name := shoppingName(ingredient.Name)
+			if ingredient.Header || name == "" {
+				continue
+			}
+
+			key := strings.ToLower(singularize(name))
+			entry, ok := entries[key]
+			if !ok {
+				entry = &shoppingEntry{
+					item:   ShoppingItem{Name: name, Aisle: AisleFor(name), Optional: true},
+					counts: make(map[string]*Quantity),
+					units:  make(map[UnitSystem]int),
+				}
+				entries[key] = entry
+				keys = append(keys, key)
+			}
+			// prefer the plural, e.g. "onions" over "onion"
+			if len(name) > len(entry.item.Name) {
+				entry.item.Name = name
+			}
+			entry.add(recipe.Name, ingredient)
+		}
+	}
+
+	items := make([]ShoppingItem, 0, len(entries))
+	for _, key := range keys {
+		items = append(items, entries[key].finish(system))
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Aisle != items[j].Aisle {
+			return aisleOrder(items[i].Aisle) < aisleOrder(items[j].Aisle)
+		}
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+	return items
+}
+
+func (e *shoppingEntry) add(recipe string, ingredient Ingredient) {
+	if !slices.Contains(e.item.Recipes, recipe) {
+		e.item.Recipes = append(e.item.Recipes, recipe)
+	}
+	e.item.Optional = e.item.Optional && ingredient.Optional
+
+	if ingredient.Quantity == nil {
+		return
+	}
+
+	m, ok := measures[ingredient.Unit]
+	if !ok {
+		if e.counts[ingredient.Unit] == nil {
+			e.order = append(e.order, ingredient.Unit)
+		}
+		total := e.counts[ingredient.Unit]
+		addQuantity(&total, *ingredient.Quantity)
+		e.counts[ingredient.Unit] = total
+		return
+	}
+
+	e.units[m.system]++
+	amount := ingredient.Quantity.Scale(m.size)
+	d, known := density(e.item.Name)
+	switch {
+	case m.dimension == mass:
+		addQuantity(&e.grams, amount)
+	case known:
+		addQuantity(&e.grams, amount.Scale(d))
+	default:
+		addQuantity(&e.ml, amount)
+	}
+}
+
+func (e *shoppingEntry) finish(system UnitSystem) ShoppingItem {
+	if system == "" {
+		system = Metric
+		if e.units[USCustomary] > e.units[Metric] {
+			system = USCustomary
+		}
+	}
+
+	item := e.item
+	for _, total := range []struct {
+		quantity *Quantity
+		unit     string
+	}{{e.grams, "g"}, {e.ml, "ml"}} {
+		if total.quantity == nil {
+			continue
+		}
+		q := *total.quantity
+		converted := Ingredient{Quantity: &q, Unit: total.unit, Name: item.Name}
+		if system == USCustomary {
+			converted = converted.Convert(USCustomary)
+		} else {
+			unit := bestUnit(Metric, measures[total.unit].dimension, q.Value)
+			q = q.Scale(1 / measures[unit].size)
+			converted = Ingredient{Quantity: &Quantity{Value: roundAmount(q.Value, Metric), Max: roundAmount(q.Max, Metric)}, Unit: unit}
+		}
+		item.Amounts = append(item.Amounts, Amount{Quantity: *converted.Quantity, Unit: converted.Unit})
+	}
+	for _, unit := range e.order {
+		item.Amounts = append(item.Amounts, Amount{Quantity: *e.counts[unit], Unit: unit})
+	}
+
+	for n := range item.Amounts {
+		if !item.Amounts[n].Quantity.IsRange() {
+			item.Amounts[n].Quantity.Max = 0
+		}
+	}
+	return item
+}
+
+// shoppingName strips what doesn't matter when shopping from an ingredient's name
+func shoppingName(name string) string {
+	name = strings.TrimSpace(name)
+	for _, suffix := range []string{" to taste", " as needed", " for serving", " for garnish"} {
+		if len(name) > len(suffix) && strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+			name = strings.TrimSpace(name[:len(name)-len(suffix)])
+		}
+	}
+	return name
+}
+
+// singularize folds simple plurals of the last word so "onions" and "onion" are the same item
+func singularize(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case len(lower) <= 3, strings.HasSuffix(lower, "ss"):
+		return name
+	case strings.HasSuffix(lower, "oes"), strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(lower, "ies"):
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(lower, "s"):
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+// aisles maps words in ingredient names to the aisle they are usually found in, in the order
+// aisles are walked through in a typical store
+var aisles = []struct {
+	aisle string
+	words []string
+}{
+	{"Produce", []string{"onion", "garlic", "shallot", "leek", "scallion", "potato", "carrot", "celery", "tomato", "pepper", "chili", "lettuce", "spinach", "kale", "cabbage", "broccoli", "cauliflower", "zucchini", "cucumber", "mushroom", "apple", "banana", "lemon", "lime", "orange", "berry", "berries", "avocado", "ginger", "parsley", "cilantro", "coriander", "basil", "mint", "thyme", "rosemary", "dill", "herb"}},
+	{"Meat & Seafood", []string{"beef", "pork", "chicken", "turkey", "lamb", "bacon", "sausage", "ham", "mince", "steak", "fish", "salmon", "tuna", "shrimp", "prawn"}},
+	{"Dairy & Eggs", []string{"milk", "butter", "cream", "cheese", "parmesan", "mozzarella", "yogurt", "yoghurt", "egg"}},
+	{"Bakery", []string{"bread", "baguette", "bun", "tortilla", "pita"}},
+	{"Baking", []string{"flour", "sugar", "baking powder", "baking soda", "yeast", "vanilla", "cocoa", "chocolate", "cornstarch", "honey", "syrup"}},
+	{"Spices & Seasonings", []string{"garlic powder", "onion powder", "chili powder", "salt", "peppercorn", "black pepper", "cumin", "paprika", "cinnamon", "nutmeg", "oregano", "turmeric", "curry", "spice", "bay leaf", "bay leaves"}},
+	{"Pantry", []string{"rice", "pasta", "spaghetti", "noodle", "oats", "bean", "lentil", "chickpea", "stock", "broth", "chicken stock", "chicken broth", "beef stock", "beef broth", "coconut milk", "peanut butter", "oil", "vinegar", "soy sauce", "mustard", "ketchup", "mayonnaise", "tomato paste", "canned", "nut", "almond"}},
+	{"Frozen", []string{"frozen"}},
+	{"Beverages", []string{"wine", "beer", "juice", "coffee", "tea"}},
+}
+
+// AisleFor guesses the grocery store aisle an ingredient is found in, or returns an empty string.
+// Longer matches win, so "black pepper" is a spice while "bell pepper" is produce.
+func AisleFor(name string) string {
+	words := " " + strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !('a' <= r && r <= 'z')
+	}), " ") + " "
+
+	best, bestLen := "", 0
+	for _, a := range aisles {
+		for _, word := range a.words {
+			// match whole words, allowing plurals
+			if (strings.Contains(words, " "+word+" ") || strings.Contains(words, " "+word+"s ") || strings.Contains(words, " "+word+"es ")) && len(word) > bestLen {
+				best, bestLen = a.aisle, len(word)
+			}
+		}
+	}
+	return best
+}
+
+// aisleOrder sorts aisles in the order of the aisles table, with unknown aisles last
+func aisleOrder(aisle string) int {
+	for n, a := range aisles {
+		if a.aisle == aisle {
+			return n
+		}
+	}
+	return len(aisles)
+}
@@ -0,0 +1,70 @@
+package paprika_test
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShoppingList(t *testing.T) {
+	bread := &paprika.Recipe{Name: "Bread", Ingredients: "2 cups flour\n1 tsp salt\n1 cup water"}
+	pizza := &paprika.Recipe{Name: "Pizza", Ingredients: "Dough:\n100 g flour\nsalt to taste\n2 onions, sliced\n1 onion\n3 cloves garlic\n1 tsp garlic, minced\nbasil (optional)"}
+
+	items := paprika.NewShoppingList(paprika.Metric, bread, pizza)
+
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, item.String())
+	}
+	assert.Equal(t, []string{
+		"basil (optional)",
+		"4.9 ml + 3 cloves garlic",
+		"3 onions",
+		"350 g flour",
+		"6 g salt",
+		"237 g water",
+	}, lines)
+
+	flour := items[3]
+	assert.Equal(t, "Baking", flour.Aisle)
+	assert.Equal(t, []string{"Bread", "Pizza"}, flour.Recipes)
+	assert.Equal(t, "Produce", items[0].Aisle)
+	assert.Empty(t, items[5].Aisle)
+}
+
+func TestNewShoppingListUnitSystem(t *testing.T) {
+	chili := &paprika.Recipe{Name: "Chili", Ingredients: "1 lb ground beef\n2-3 tbsp chili powder\n1 cup stock"}
+	soup := &paprika.Recipe{Name: "Soup", Ingredients: "1 lb ground beef\n1 tbsp chili powder\n250 ml stock"}
+
+	items := paprika.NewShoppingList("", chili, soup)
+	require.Len(t, items, 3)
+	assert.Equal(t, "2 lb ground beef", items[0].String())
+	assert.Equal(t, "3-4 tbsp chili powder", items[1].String())
+	// amounts given in both systems equally often are rendered in metric
+	assert.Equal(t, "487 ml stock", items[2].String())
+
+	items = paprika.NewShoppingList(paprika.Metric, chili, soup)
+	assert.Equal(t, "907 g ground beef", items[0].String())
+	items = paprika.NewShoppingList(paprika.USCustomary, chili, soup)
+	assert.Equal(t, "2 cups stock", items[2].String())
+}
+
+func TestNewShoppingListOrMore(t *testing.T) {
+	cake := &paprika.Recipe{Name: "Cake", Ingredients: "2 or more eggs"}
+	omelette := &paprika.Recipe{Name: "Omelette", Ingredients: "3 eggs"}
+
+	items := paprika.NewShoppingList(paprika.Metric, cake, omelette)
+	require.Len(t, items, 1)
+	assert.Equal(t, "eggs", items[0].Name)
+	assert.Equal(t, "Dairy & Eggs", items[0].Aisle)
+}
+
+func TestAisleFor(t *testing.T) {
+	assert.Equal(t, "Produce", paprika.AisleFor("red bell peppers"))
+	assert.Equal(t, "Spices & Seasonings", paprika.AisleFor("freshly ground black pepper"))
+	assert.Equal(t, "Pantry", paprika.AisleFor("chicken stock"))
+	assert.Equal(t, "Meat & Seafood", paprika.AisleFor("chicken thighs"))
+	assert.Empty(t, paprika.AisleFor("tofu"))
+}
@@ -0,0 +1,212 @@
+package paprika
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SyncOptions configures SyncRecipes
+type SyncOptions struct {
+	// DryRun reports what would change without saving anything
+	DryRun bool
+}
+
+// SyncResult lists the names of the recipes SyncRecipes created, updated and left alone
+type SyncResult struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+	// Saved are the recipes as they were saved; it is empty for a dry run
+	Saved []*Recipe
+}
+
+// SyncRecipes saves recipes from another source, like a folder of Cooklang files, to the account.
+// Each recipe updates the recipe with the same UID or, failing that, the same name, and is created
+// otherwise. Only the fields the source has are updated: photos, ratings and the like are kept.
+// The recipes' Categories hold category names rather than UIDs.
+func (c *Client) SyncRecipes(ctx context.Context, recipes []*Recipe, opts SyncOptions) (*SyncResult, error) {
+	list, err := c.ListRecipes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := c.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.DryRun {
+		// create the missing categories of all recipes at once, then map names to UIDs locally
+		var names []string
+		for _, incoming := range recipes {
+			names = append(names, incoming.Categories...)
+		}
+		if categories, err = c.addCategories(ctx, categories, names); err != nil {
+			return nil, err
+		}
+	}
+
+	existing := newSyncIndex(c, list)
+	// saved are the UIDs of the recipes saved so far, which the list doesn't have the current hash of
+	saved := make(map[string]bool)
+	var result SyncResult
+	for _, incoming := range recipes {
+		current, err := existing.match(ctx, incoming)
+		if err != nil {
+			return nil, err
+		}
+
+		if current == nil {
+			result.Created = append(result.Created, incoming.Name)
+			if opts.DryRun {
+				continue
+			}
+			recipe := *incoming
+			recipe.Categories, _ = categories.UIDs(recipe.Categories)
+			created, err := c.SaveRecipe(ctx, recipe)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create %s: %w", recipe.Name, err)
+			}
+			result.Saved = append(result.Saved, created)
+			saved[strings.ToUpper(created.UID)] = true
+			// a second file with the same name updates this recipe rather than creating another
+			existing.add(created)
+			continue
+		}
+
+		updated := mergeSyncedRecipe(*current, incoming)
+		uids, missing := categories.UIDs(incoming.Categories)
+		if len(missing) == 0 && sameStrings(uids, current.Categories) && sameRecipe(updated, *current) {
+			result.Unchanged = append(result.Unchanged, incoming.Name)
+			continue
+		}
+
+		result.Updated = append(result.Updated, incoming.Name)
+		if opts.DryRun {
+			continue
+		}
+		updated.Categories = uids
+		saveOpts := []SaveOption{WithExpectedHash(current.Hash)}
+		if !saved[strings.ToUpper(current.UID)] {
+			saveOpts = append(saveOpts, WithRecipeList(list))
+		}
+		recipe, err := c.SaveRecipe(ctx, updated, saveOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", updated.Name, err)
+		}
+		result.Saved = append(result.Saved, recipe)
+		saved[strings.ToUpper(recipe.UID)] = true
+		existing.add(recipe)
+	}
+
+	return &result, nil
+}
+
+// syncIndex finds the recipes synced recipes update. Recipes are downloaded as they are needed:
+// matching by UID only downloads that recipe, and the rest are only downloaded to match by name.
+type syncIndex struct {
+	client *Client
+	list   *RecipeList
+	// listed are the UIDs in the list
+	listed  map[string]bool
+	byUID   map[string]*Recipe
+	byName  map[string]*Recipe
+	fetched bool
+}
+
+func newSyncIndex(c *Client, list *RecipeList) *syncIndex {
+	listed := make(map[string]bool, len(list.Result))
+	for _, item := range list.Result {
+		listed[strings.ToUpper(item.UID)] = true
+	}
+	return &syncIndex{client: c, list: list, listed: listed, byUID: make(map[string]*Recipe), byName: make(map[string]*Recipe)}
+}
+
+// match returns the recipe with the incoming recipe's UID or, failing that, its name, or nil if there is none.
+// Recipes in the trash don't match.
+func (x *syncIndex) match(ctx context.Context, incoming *Recipe) (*Recipe, error) {
+	if incoming.UID != "" {
+		recipe, err := x.get(ctx, incoming.UID)
+		if err != nil || recipe != nil {
+			return recipe, err
+		}
+	}
+
+	if !x.fetched {
+		for _, item := range x.list.Result {
+			if _, err := x.get(ctx, item.UID); err != nil {
+				return nil, err
+			}
+		}
+		x.fetched = true
+	}
+	return x.byName[syncName(incoming.Name)], nil
+}
+
+// get returns the recipe with the given UID, downloading it if the list has it
+func (x *syncIndex) get(ctx context.Context, uid string) (*Recipe, error) {
+	uid = strings.ToUpper(uid)
+	if recipe, ok := x.byUID[uid]; ok {
+		return recipe, nil
+	}
+	if !x.listed[uid] {
+		return nil, nil
+	}
+
+	recipe, err := x.client.GetRecipe(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if recipe.InTrash {
+		x.byUID[uid] = nil
+		return nil, nil
+	}
+	x.add(recipe)
+	return recipe, nil
+}
+
+// add records a recipe, e.g. after it was saved
+func (x *syncIndex) add(recipe *Recipe) {
+	x.byUID[strings.ToUpper(recipe.UID)] = recipe
+	x.byName[syncName(recipe.Name)] = recipe
+}
+
+func syncName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// mergeSyncedRecipe copies the fields a synced recipe sets over the current one, apart from the categories
+func mergeSyncedRecipe(current Recipe, incoming *Recipe) Recipe {
+	merged := current
+	merged.Name = incoming.Name
+	merged.Ingredients = incoming.Ingredients
+	merged.Directions = incoming.Directions
+	merged.Description = incoming.Description
+	merged.Notes = incoming.Notes
+	merged.Servings = incoming.Servings
+	merged.PrepTime = incoming.PrepTime
+	merged.CookTime = incoming.CookTime
+	merged.TotalTime = incoming.TotalTime
+	merged.Difficulty = incoming.Difficulty
+	merged.Source = incoming.Source
+	merged.SourceURL = incoming.SourceURL
+	if incoming.ImageURL != "" {
+		merged.ImageURL = incoming.ImageURL
+	}
+	return merged
+}
+
+// sameRecipe compares two versions of a recipe, ignoring their categories
+func sameRecipe(a, b Recipe) bool {
+	a.Categories, b.Categories = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// sameStrings reports whether a and b hold the same strings, in any order
+func sameStrings(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
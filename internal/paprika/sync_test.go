@@ -0,0 +1,81 @@
+package paprika_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncRecipes(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	srv.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup", Ingredients: "1 onion", Directions: "Cook.", Categories: []string{"DINNER"}, Rating: 5, PhotoURL: "https://example.com/soup.jpg"})
+	srv.PutRecipe(paprika.Recipe{UID: "STEW", Name: "Stew", Ingredients: "1 carrot", Directions: "Simmer."})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	recipes := []*paprika.Recipe{
+		{Name: "soup", Ingredients: "2 onions", Directions: "Cook.", Categories: []string{"dinner"}},
+		{UID: "STEW", Name: "Stew", Ingredients: "1 carrot", Directions: "Simmer."},
+		{Name: "Toast", Directions: "Toast the bread.", Categories: []string{"Breakfast"}},
+	}
+
+	dryRun, err := client.SyncRecipes(ctx, recipes, paprika.SyncOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Toast"}, dryRun.Created)
+	assert.Equal(t, []string{"soup"}, dryRun.Updated)
+	assert.Equal(t, []string{"Stew"}, dryRun.Unchanged)
+	assert.Empty(t, dryRun.Saved)
+	assert.Len(t, srv.Recipes(), 2)
+
+	result, err := client.SyncRecipes(ctx, recipes, paprika.SyncOptions{})
+	require.NoError(t, err)
+	assert.Len(t, result.Saved, 2)
+
+	soup, ok := srv.Recipe("SOUP")
+	require.True(t, ok)
+	assert.Equal(t, "2 onions", soup.Ingredients)
+	assert.Equal(t, 5, soup.Rating)
+	assert.Equal(t, "https://example.com/soup.jpg", soup.PhotoURL)
+	assert.Equal(t, []string{"DINNER"}, soup.Categories)
+	assert.Len(t, srv.Recipes(), 3)
+
+	again, err := client.SyncRecipes(ctx, recipes, paprika.SyncOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, again.Created)
+	assert.Empty(t, again.Updated)
+	assert.Len(t, again.Unchanged, 3)
+}
+
+func TestSyncRecipesRequests(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup", Directions: "Cook."})
+	srv.PutRecipe(paprika.Recipe{UID: "STEW", Name: "Stew", Directions: "Simmer."})
+	srv.PutRecipe(paprika.Recipe{UID: "TOAST", Name: "Toast", Directions: "Toast."})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// recipes matched by UID are the only ones downloaded
+	recipes := []*paprika.Recipe{
+		{UID: "SOUP", Name: "Soup", Directions: "Cook well.", Categories: []string{"Dinner"}},
+		{UID: "STEW", Name: "Stew", Directions: "Simmer longer.", Categories: []string{"Dinner"}},
+	}
+	result, err := client.SyncRecipes(ctx, recipes, paprika.SyncOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Soup", "Stew"}, result.Updated)
+	assert.Equal(t, 2, srv.Requests("GET", "/api/v2/sync/recipe/"))
+	assert.Equal(t, 1, srv.Requests("GET", "/api/v2/sync/recipes"))
+	assert.Equal(t, 1, srv.Requests("GET", "/api/v2/sync/categories"))
+	assert.Equal(t, 1, srv.Requests("POST", "/api/v2/sync/categories"))
+	assert.Len(t, srv.Categories(), 1)
+}
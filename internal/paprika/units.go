@@ -0,0 +1,245 @@
+package paprika
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// UnitSystem is a system of measurement recipes can be converted to
+type UnitSystem string
+
+const (
+	Metric      UnitSystem = "metric"
+	USCustomary UnitSystem = "us"
+)
+
+// ParseUnitSystem parses "metric" or "us", ignoring case
+func ParseUnitSystem(s string) (UnitSystem, error) {
+	switch system := UnitSystem(strings.ToLower(strings.TrimSpace(s))); system {
+	case Metric, USCustomary:
+		return system, nil
+	default:
+		return "", fmt.Errorf("unknown unit system %q, expected metric or us", s)
+	}
+}
+
+type dimension int
+
+const (
+	volume dimension = iota + 1
+	mass
+)
+
+// measure describes a unit that can be converted: its size in millilitres or grams
+type measure struct {
+	dimension dimension
+	size      float64
+	system    UnitSystem
+}
+
+var measures = map[string]measure{
+	"tsp":    {volume, 4.92892, USCustomary},
+	"tbsp":   {volume, 14.7868, USCustomary},
+	"fl oz":  {volume, 29.5735, USCustomary},
+	"cup":    {volume, 236.588, USCustomary},
+	"pint":   {volume, 473.176, USCustomary},
+	"quart":  {volume, 946.353, USCustomary},
+	"gallon": {volume, 3785.41, USCustomary},
+	"ml":     {volume, 1, Metric},
+	"cl":     {volume, 10, Metric},
+	"dl":     {volume, 100, Metric},
+	"l":      {volume, 1000, Metric},
+	"oz":     {mass, 28.3495, USCustomary},
+	"lb":     {mass, 453.592, USCustomary},
+	"mg":     {mass, 0.001, Metric},
+	"g":      {mass, 1, Metric},
+	"kg":     {mass, 1000, Metric},
+}
+
+// densities are the weights of a cup of common ingredients in grams, so they can be converted
+// between cups and grams. More specific names come first.
+var densities = []struct {
+	name        string
+	gramsPerCup float64
+}{
+	{"whole wheat flour", 120},
+	{"bread flour", 127},
+	{"almond flour", 96},
+	{"flour", 125},
+	{"brown sugar", 213},
+	{"powdered sugar", 120},
+	{"icing sugar", 120},
+	{"confectioners sugar", 120},
+	{"sugar", 200},
+	{"butter", 227},
+	{"cocoa powder", 85},
+	{"cocoa", 85},
+	{"rolled oats", 90},
+	{"oats", 90},
+	{"rice", 185},
+	{"honey", 340},
+	{"maple syrup", 315},
+	{"olive oil", 216},
+	{"oil", 218},
+	{"milk", 245},
+	{"cream", 238},
+	{"yogurt", 245},
+	{"water", 237},
+	{"salt", 288},
+}
+
+// density returns the density of an ingredient in grams per millilitre, if it is known
+func density(name string) (float64, bool) {
+	words := " " + strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !('a' <= r && r <= 'z')
+	}), " ") + " "
+
+	for _, d := range densities {
+		if strings.Contains(words, " "+d.name+" ") {
+			return d.gramsPerCup / measures["cup"].size, true
+		}
+	}
+	return 0, false
+}
+
+// Convert returns the ingredient with its quantity converted to the given unit system.
+// Ingredients with a known density are converted between volume and weight, so cups of
+// flour become grams and grams of butter become cups. Ingredients without a quantity or
+// a convertible unit are returned as they are.
+func (i Ingredient) Convert(system UnitSystem) Ingredient {
+	from, ok := measures[i.Unit]
+	if i.Quantity == nil || !ok || from.system == system {
+		return i
+	}
+
+	// convert to millilitres or grams first
+	amount := i.Quantity.Scale(from.size)
+	dim := from.dimension
+	if d, ok := density(i.Name); ok {
+		switch {
+		case system == Metric && dim == volume:
+			amount, dim = amount.Scale(d), mass
+		case system == USCustomary && dim == mass:
+			amount, dim = amount.Scale(1/d), volume
+		}
+	}
+
+	unit := bestUnit(system, dim, amount.Value)
+	converted := amount.Scale(1 / measures[unit].size)
+	converted = Quantity{Value: roundAmount(converted.Value, system), Max: roundAmount(converted.Max, system)}
+
+	i.Quantity = &converted
+	i.Unit = unit
+	return i
+}
+
+// bestUnit picks the unit of a system that suits an amount in millilitres or grams
+func bestUnit(system UnitSystem, dim dimension, amount float64) string {
+	switch {
+	case system == Metric && dim == volume:
+		if amount >= 1000 {
+			return "l"
+		}
+		return "ml"
+	case system == Metric:
+		if amount >= 1000 {
+			return "kg"
+		}
+		return "g"
+	case dim == volume:
+		switch {
+		case amount < measures["tbsp"].size:
+			return "tsp"
+		case amount < measures["cup"].size/4:
+			return "tbsp"
+		}
+		return "cup"
+	default:
+		if amount >= measures["lb"].size {
+			return "lb"
+		}
+		return "oz"
+	}
+}
+
+// roundAmount rounds a converted amount to what a cook would measure: whole grams and
+// millilitres, or the nearest eighth or third in US customary units
+func roundAmount(v float64, system UnitSystem) float64 {
+	switch {
+	case v == 0:
+		return 0
+	case v >= 10:
+		return math.Round(v)
+	case system == Metric:
+		return math.Round(v*10) / 10
+	}
+
+	eighths := math.Round(v*8) / 8
+	thirds := math.Round(v*3) / 3
+	rounded := eighths
+	if math.Abs(v-thirds) < math.Abs(v-eighths) {
+		rounded = thirds
+	}
+	return math.Max(rounded, 0.125)
+}
+
+// temperaturePattern finds temperatures like "350°F", "180 °C" or "200 degrees Celsius"
+var temperaturePattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(°|º|degrees?\s+)?\s*(fahrenheit|celsius|f|c)\b`)
+
+// ConvertTemperatures converts the temperatures in text, e.g. directions, to the given unit system
+func ConvertTemperatures(text string, system UnitSystem) string {
+	return temperaturePattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := temperaturePattern.FindStringSubmatch(match)
+		value, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return match
+		}
+		// without a degree sign, "2 C" is more likely cups than Celsius
+		if m[2] == "" && len(m[3]) == 1 && value < 100 {
+			return match
+		}
+
+		scale := strings.ToLower(m[3][:1])
+		switch {
+		case scale == "f" && system == Metric:
+			return formatTemperature((value-32)*5/9, "C", 10)
+		case scale == "c" && system == USCustomary:
+			return formatTemperature(value*9/5+32, "F", 25)
+		}
+		return match
+	})
+}
+
+// formatTemperature rounds oven temperatures to the steps ovens are set in, e.g. 350°F for
+// 180°C, and lower temperatures to the degree
+func formatTemperature(value float64, scale string, step float64) string {
+	if value >= 120 {
+		value = math.Round(value/step) * step
+	}
+	return fmt.Sprintf("%d°%s", int(math.Round(value)), scale)
+}
+
+// Converted returns a copy of the recipe with its ingredients and the temperatures in
+// its directions converted to the given unit system
+func (r *Recipe) Converted(system UnitSystem) *Recipe {
+	converted := *r
+	converted.Categories = append([]string(nil), r.Categories...)
+
+	lines := strings.Split(r.Ingredients, "\n")
+	for n, line := range lines {
+		ingredient := ParseIngredient(line)
+		if ingredient.Header {
+			continue
+		}
+		if c := ingredient.Convert(system); c.Unit != ingredient.Unit {
+			lines[n] = c.String()
+		}
+	}
+	converted.Ingredients = strings.Join(lines, "\n")
+	converted.Directions = ConvertTemperatures(r.Directions, system)
+
+	return &converted
+}
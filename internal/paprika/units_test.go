@@ -0,0 +1,61 @@
+package paprika_test
+
+import (
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngredientConvert(t *testing.T) {
+	tests := []struct {
+		line   string
+		system paprika.UnitSystem
+		want   string
+	}{
+		{"2 cups flour, sifted", paprika.Metric, "250 g flour, sifted"},
+		{"1 cup milk", paprika.Metric, "245 g milk"},
+		{"1 cup chicken stock", paprika.Metric, "237 ml chicken stock"},
+		{"2 tbsp olive oil", paprika.Metric, "27 g olive oil"},
+		{"1 lb ground beef", paprika.Metric, "454 g ground beef"},
+		{"3 lb potatoes", paprika.Metric, "1.4 kg potatoes"},
+		{"2-3 tsp vinegar", paprika.Metric, "9.9-15 ml vinegar"},
+		{"227 g butter", paprika.USCustomary, "1 cup butter"},
+		{"500 g beef", paprika.USCustomary, "1 1/8 lb beef"},
+		{"100 g cheese", paprika.USCustomary, "3 1/2 oz cheese"},
+		{"250 ml stock", paprika.USCustomary, "1 cup stock"},
+		{"10 ml soy sauce", paprika.USCustomary, "2 tsp soy sauce"},
+		{"2 eggs", paprika.Metric, "2 eggs"},
+		{"1 cup flour", paprika.USCustomary, "1 cup flour"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, paprika.ParseIngredient(tt.line).Convert(tt.system).String())
+		})
+	}
+}
+
+func TestConvertTemperatures(t *testing.T) {
+	assert.Equal(t, "Bake at 180°C for 20 minutes", paprika.ConvertTemperatures("Bake at 350°F for 20 minutes", paprika.Metric))
+	assert.Equal(t, "Preheat to 200°C", paprika.ConvertTemperatures("Preheat to 400 degrees Fahrenheit", paprika.Metric))
+	assert.Equal(t, "Bake at 350°F, then at 425°F", paprika.ConvertTemperatures("Bake at 180C, then at 220 °C", paprika.USCustomary))
+	assert.Equal(t, "Warm to 43°C", paprika.ConvertTemperatures("Warm to 110°F", paprika.Metric))
+	assert.Equal(t, "Add 2 C flour", paprika.ConvertTemperatures("Add 2 C flour", paprika.USCustomary))
+}
+
+func TestRecipeToMarkdownUnits(t *testing.T) {
+	recipe := paprika.Recipe{Name: "Cake", Ingredients: "Batter:\n1 cup sugar\n2 eggs", Directions: "Bake at 350°F"}
+
+	markdown := recipe.ToMarkdown(paprika.WithUnits(paprika.Metric))
+	assert.Contains(t, markdown, "- Batter:\n- 200 g sugar\n- 2 eggs\n")
+	assert.Contains(t, markdown, "1. Bake at 180°C\n")
+	assert.Equal(t, "1 cup sugar", recipe.ParsedIngredients()[1].Raw)
+
+	_, err := paprika.ParseUnitSystem("imperial")
+	assert.Error(t, err)
+	system, err := paprika.ParseUnitSystem("US")
+	require.NoError(t, err)
+	assert.Equal(t, paprika.USCustomary, system)
+}
@@ -0,0 +1,562 @@
+package paprika
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VaultExtension is the file extension of the notes in a Markdown vault
+const VaultExtension = ".md"
+
+// vaultFrontMatter holds the fields of a recipe that fit on one line. The photo URL is left
+// out: it is a download link that expires, not part of the recipe.
+type vaultFrontMatter struct {
+	UID           string   `yaml:"uid,omitempty"`
+	Hash          string   `yaml:"hash,omitempty"`
+	Categories    []string `yaml:"categories,omitempty"`
+	Rating        int      `yaml:"rating,omitempty"`
+	Servings      string   `yaml:"servings,omitempty"`
+	PrepTime      string   `yaml:"prep_time,omitempty"`
+	CookTime      string   `yaml:"cook_time,omitempty"`
+	TotalTime     string   `yaml:"total_time,omitempty"`
+	Difficulty    string   `yaml:"difficulty,omitempty"`
+	Source        string   `yaml:"source,omitempty"`
+	SourceURL     string   `yaml:"source_url,omitempty"`
+	ImageURL      string   `yaml:"image_url,omitempty"`
+	Created       string   `yaml:"created,omitempty"`
+	Scale         string   `yaml:"scale,omitempty"`
+	Photo         string   `yaml:"photo,omitempty"`
+	PhotoHash     string   `yaml:"photo_hash,omitempty"`
+	PhotoLarge    string   `yaml:"photo_large,omitempty"`
+	IsPinned      bool     `yaml:"is_pinned,omitempty"`
+	OnFavorites   bool     `yaml:"on_favorites,omitempty"`
+	OnGroceryList bool     `yaml:"on_grocery_list,omitempty"`
+	InTrash       bool     `yaml:"in_trash,omitempty"`
+}
+
+// vaultSections are the headings of the multi-line fields in a note's body, in the order they are written
+var vaultSections = []struct {
+	heading string
+	field   func(r *Recipe) *string
+}{
+	{"Description", func(r *Recipe) *string { return &r.Description }},
+	{"Ingredients", func(r *Recipe) *string { return &r.Ingredients }},
+	{"Directions", func(r *Recipe) *string { return &r.Directions }},
+	{"Notes", func(r *Recipe) *string { return &r.Notes }},
+	{"Nutrition", func(r *Recipe) *string { return &r.NutritionalInfo }},
+}
+
+// EncodeVaultNote renders a recipe as a Markdown note with YAML front matter, e.g. for an Obsidian vault.
+// The name is the note's title and the multi-line fields are sections of its body; everything else is
+// in the front matter. categories are the names of the recipe's categories.
+func EncodeVaultNote(r *Recipe, categories []string) []byte {
+	front := vaultFrontMatter{
+		UID:           r.UID,
+		Hash:          r.Hash,
+		Categories:    categories,
+		Rating:        r.Rating,
+		Servings:      r.Servings,
+		PrepTime:      r.PrepTime,
+		CookTime:      r.CookTime,
+		TotalTime:     r.TotalTime,
+		Difficulty:    r.Difficulty,
+		Source:        r.Source,
+		SourceURL:     r.SourceURL,
+		ImageURL:      r.ImageURL,
+		Created:       r.Created,
+		Scale:         r.Scale,
+		Photo:         r.Photo,
+		PhotoHash:     r.PhotoHash,
+		PhotoLarge:    r.PhotoLarge,
+		IsPinned:      r.IsPinned,
+		OnFavorites:   r.OnFavorites,
+		OnGroceryList: r.OnGroceryList,
+		InTrash:       r.InTrash,
+	}
+	// marshalling a struct of plain fields can't fail
+	data, _ := yaml.Marshal(front)
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	if string(data) != "{}\n" {
+		buf.Write(data)
+	}
+	buf.WriteString("---\n\n")
+	buf.WriteString("# " + r.Name + "\n")
+
+	for _, section := range vaultSections {
+		text := strings.Trim(*section.field(r), "\n")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		buf.WriteString("\n## " + section.heading + "\n\n" + text + "\n")
+	}
+	return buf.Bytes()
+}
+
+// DecodeVaultNote parses a note written by EncodeVaultNote. The recipe's Categories holds
+// category names rather than UIDs.
+func DecodeVaultNote(data []byte) (*Recipe, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, errors.New("note has no front matter")
+	}
+	// the front matter ends at a line that is only "---", which may follow straight away if it's empty
+	front, body, ok := strings.Cut("\n"+text[len("---\n"):], "\n---\n")
+	if !ok {
+		return nil, errors.New("note's front matter isn't closed")
+	}
+
+	var fm vaultFrontMatter
+	if err := yaml.Unmarshal([]byte(front), &fm); err != nil {
+		return nil, fmt.Errorf("invalid front matter: %w", err)
+	}
+	r := &Recipe{
+		UID:           fm.UID,
+		Hash:          fm.Hash,
+		Categories:    fm.Categories,
+		Rating:        fm.Rating,
+		Servings:      fm.Servings,
+		PrepTime:      fm.PrepTime,
+		CookTime:      fm.CookTime,
+		TotalTime:     fm.TotalTime,
+		Difficulty:    fm.Difficulty,
+		Source:        fm.Source,
+		SourceURL:     fm.SourceURL,
+		ImageURL:      fm.ImageURL,
+		Created:       fm.Created,
+		Scale:         fm.Scale,
+		Photo:         fm.Photo,
+		PhotoHash:     fm.PhotoHash,
+		PhotoLarge:    fm.PhotoLarge,
+		IsPinned:      fm.IsPinned,
+		OnFavorites:   fm.OnFavorites,
+		OnGroceryList: fm.OnGroceryList,
+		InTrash:       fm.InTrash,
+	}
+
+	var section *string
+	var lines []string
+	flush := func() {
+		if section != nil {
+			*section = strings.Trim(strings.Join(lines, "\n"), "\n")
+		}
+		lines = nil
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			if field := vaultSection(heading); field != nil {
+				flush()
+				section = field(r)
+				continue
+			}
+		}
+		if title, ok := strings.CutPrefix(line, "# "); ok && section == nil && r.Name == "" {
+			r.Name = strings.TrimSpace(title)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	if r.Name == "" {
+		return nil, errors.New("note has no title")
+	}
+	return r, nil
+}
+
+func vaultSection(heading string) func(r *Recipe) *string {
+	for _, section := range vaultSections {
+		if strings.EqualFold(strings.TrimSpace(heading), section.heading) {
+			return section.field
+		}
+	}
+	return nil
+}
+
+// vaultStateFile remembers the checksum of every note as it was last written, to tell local edits from remote ones
+const vaultStateFile = ".paprika-sync.json"
+
+// vaultState is what SyncVault remembers about a recipe: the hash it had in Paprika and
+// the checksum of its note, which is empty for recipes in the trash
+type vaultState struct {
+	Hash     string `json:"hash"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// VaultOptions configures SyncVault
+type VaultOptions struct {
+	// DryRun reports what would change without writing notes or saving recipes
+	DryRun bool
+}
+
+// VaultConflict is a note SyncVault left alone because its recipe changed in Paprika too, or it couldn't be read or saved
+type VaultConflict struct {
+	Name   string
+	Path   string
+	Reason string
+}
+
+// VaultResult lists the names of the recipes SyncVault synced, by what it did with them
+type VaultResult struct {
+	// Pulled are written to the vault from Paprika
+	Pulled []string
+	// Pushed are saved to Paprika from the vault
+	Pushed []string
+	// Removed were deleted or moved to the trash in Paprika, so their notes were deleted
+	Removed   []string
+	Unchanged int
+	Conflicts []VaultConflict
+	// Saved are the recipes as they were pushed; it is empty for a dry run
+	Saved []*Recipe
+}
+
+// vaultNote is a note found in the vault
+type vaultNote struct {
+	path     string
+	recipe   *Recipe
+	checksum string
+}
+
+// SyncVault mirrors the recipes into a folder of Markdown notes, e.g. an Obsidian vault, and saves notes
+// edited in the folder back to Paprika. A recipe edited in the vault and in Paprika since the last sync is
+// reported as a conflict and left alone on both sides. New notes are created as recipes.
+func (c *Client) SyncVault(ctx context.Context, dir string, opts VaultOptions) (*VaultResult, error) {
+	s := &vaultSync{client: c, dir: dir, opts: opts, taken: make(map[string]bool)}
+	var err error
+	if s.state, err = readVaultState(dir); err != nil {
+		return nil, err
+	}
+	notes, newNotes, err := s.readNotes()
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := c.ListRecipes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.list = list
+	if s.categories, err = c.ListCategories(ctx); err != nil {
+		return nil, err
+	}
+	if !opts.DryRun {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, item := range list.Result {
+		note := notes[item.UID]
+		delete(notes, item.UID)
+		if err := s.syncRecipe(ctx, item.UID, item.Hash, note); err != nil {
+			return nil, err
+		}
+	}
+
+	// notes of recipes Paprika doesn't have
+	orphans := slices.SortedFunc(maps.Values(notes), func(a, b *vaultNote) int { return strings.Compare(a.path, b.path) })
+	for _, note := range orphans {
+		if err := s.syncOrphan(ctx, note); err != nil {
+			return nil, err
+		}
+	}
+	for _, note := range newNotes {
+		if err := s.push(ctx, note, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if !opts.DryRun {
+		if err := writeVaultState(dir, s.state); err != nil {
+			return nil, err
+		}
+	}
+	return &s.result, nil
+}
+
+type vaultSync struct {
+	client *Client
+	dir    string
+	opts   VaultOptions
+	state  map[string]vaultState
+	// list is the recipe list the sync started with, which pushes check the recipes' hashes against
+	list       *RecipeList
+	categories Categories
+	// taken are the paths of the notes, so new notes get names of their own
+	taken  map[string]bool
+	result VaultResult
+}
+
+func readVaultState(dir string) (map[string]vaultState, error) {
+	state := make(map[string]vaultState)
+	data, err := os.ReadFile(filepath.Join(dir, vaultStateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", vaultStateFile, err)
+	}
+	return state, nil
+}
+
+func writeVaultState(dir string, state map[string]vaultState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, vaultStateFile), data, 0o644)
+}
+
+// readNotes finds the notes in the vault, by UID. Notes without a UID are new. Hidden folders like .obsidian are skipped.
+func (s *vaultSync) readNotes() (map[string]*vaultNote, []*vaultNote, error) {
+	notes := make(map[string]*vaultNote)
+	var newNotes []*vaultNote
+
+	err := filepath.WalkDir(s.dir, func(path string, d os.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) && path == s.dir {
+			return filepath.SkipDir
+		}
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir() && path != s.dir && strings.HasPrefix(d.Name(), "."):
+			return filepath.SkipDir
+		case d.IsDir() || filepath.Ext(path) != VaultExtension:
+			return nil
+		}
+
+		s.taken[path] = true
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		recipe, err := DecodeVaultNote(data)
+		if err != nil {
+			s.conflict(strings.TrimSuffix(d.Name(), VaultExtension), path, err.Error())
+			return nil
+		}
+
+		note := &vaultNote{path: path, recipe: recipe, checksum: checksum(data)}
+		switch existing, ok := notes[recipe.UID]; {
+		case recipe.UID == "":
+			newNotes = append(newNotes, note)
+		case ok:
+			s.conflict(recipe.Name, path, fmt.Sprintf("has the same uid as %s", existing.path))
+		default:
+			notes[recipe.UID] = note
+		}
+		return nil
+	})
+	return notes, newNotes, err
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *vaultSync) conflict(name, path, reason string) {
+	s.result.Conflicts = append(s.result.Conflicts, VaultConflict{Name: name, Path: path, Reason: reason})
+}
+
+// edited reports whether a note was edited since it was last written. It isn't known
+// if the vault has no checksum for the note, e.g. because it was copied from elsewhere.
+func (s *vaultSync) edited(note *vaultNote) (edited, known bool) {
+	st, ok := s.state[note.recipe.UID]
+	if !ok || st.Checksum == "" {
+		return false, false
+	}
+	return note.checksum != st.Checksum, true
+}
+
+// syncRecipe syncs a recipe Paprika has with its note, which is nil if there is none
+func (s *vaultSync) syncRecipe(ctx context.Context, uid, hash string, note *vaultNote) error {
+	if note == nil {
+		if st, ok := s.state[uid]; ok && st.Checksum == "" && st.Hash == hash {
+			// still in the trash
+			return nil
+		}
+		// new in Paprika, or its note was deleted from the vault
+		return s.pull(ctx, uid, nil, nil)
+	}
+
+	remoteChanged := note.recipe.Hash != hash
+	edited, known := s.edited(note)
+	var remote *Recipe
+	if !known {
+		// the note was written from the recipe with the hash in its front matter, so if
+		// that is still the recipe in Paprika, comparing them tells if the note was edited
+		var err error
+		if remote, err = s.client.GetRecipe(ctx, uid); err != nil {
+			return err
+		}
+		edited = !s.sameAsRemote(note, remote)
+		if remoteChanged && edited {
+			s.conflict(note.recipe.Name, note.path, "differs from Paprika and the vault has no record of syncing it")
+			return nil
+		}
+	}
+
+	switch {
+	case !remoteChanged && !edited:
+		s.result.Unchanged++
+		s.state[uid] = vaultState{Hash: hash, Checksum: note.checksum}
+		return nil
+	case !edited:
+		return s.pull(ctx, uid, note, remote)
+	case !remoteChanged:
+		return s.push(ctx, note, hash)
+	}
+
+	remote, err := s.client.GetRecipe(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if s.sameAsRemote(note, remote) {
+		// both sides made the same edit
+		return s.pull(ctx, uid, note, remote)
+	}
+	s.conflict(note.recipe.Name, note.path, "changed in Paprika and in the vault since the last sync")
+	return nil
+}
+
+// sameAsRemote compares a note with a recipe from Paprika
+func (s *vaultSync) sameAsRemote(note *vaultNote, remote *Recipe) bool {
+	local := *note.recipe
+	local.Hash = remote.Hash
+	return bytes.Equal(EncodeVaultNote(&local, local.Categories), EncodeVaultNote(remote, s.categories.Names(remote.Categories)))
+}
+
+// pull writes a recipe to its note, downloading it unless it was already. Notes of recipes
+// in the trash are deleted, and notes of renamed recipes are renamed too.
+func (s *vaultSync) pull(ctx context.Context, uid string, note *vaultNote, remote *Recipe) error {
+	if remote == nil {
+		var err error
+		if remote, err = s.client.GetRecipe(ctx, uid); err != nil {
+			return err
+		}
+	}
+
+	if remote.InTrash {
+		s.state[uid] = vaultState{Hash: remote.Hash}
+		if note != nil {
+			s.result.Removed = append(s.result.Removed, remote.Name)
+			return s.remove(note.path)
+		}
+		return nil
+	}
+
+	path := ""
+	if note != nil {
+		path = note.path
+		if note.recipe.Name != remote.Name {
+			if err := s.remove(path); err != nil {
+				return err
+			}
+			path = s.newPath(remote.Name, filepath.Dir(path))
+		}
+	} else {
+		path = s.newPath(remote.Name, s.dir)
+	}
+
+	s.result.Pulled = append(s.result.Pulled, remote.Name)
+	return s.write(path, remote, s.categories.Names(remote.Categories))
+}
+
+// push saves a note to Paprika. expectedHash is the hash the recipe should still have in Paprika,
+// or empty for notes of new recipes.
+func (s *vaultSync) push(ctx context.Context, note *vaultNote, expectedHash string) error {
+	s.result.Pushed = append(s.result.Pushed, note.recipe.Name)
+	if s.opts.DryRun {
+		return nil
+	}
+
+	names := note.recipe.Categories
+	recipe := *note.recipe
+	recipe.Hash = ""
+	// categories are listed once per sync, and only created here if the note has new ones
+	var err error
+	if s.categories, err = s.client.addCategories(ctx, s.categories, names); err != nil {
+		return err
+	}
+	recipe.Categories, _ = s.categories.UIDs(names)
+
+	var opts []SaveOption
+	if expectedHash != "" {
+		opts = append(opts, WithExpectedHash(expectedHash), WithRecipeList(s.list))
+	}
+	saved, err := s.client.SaveRecipe(ctx, recipe, opts...)
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		s.result.Pushed = s.result.Pushed[:len(s.result.Pushed)-1]
+		s.conflict(note.recipe.Name, note.path, "changed in Paprika while syncing")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %w", note.recipe.Name, err)
+	}
+
+	s.result.Saved = append(s.result.Saved, saved)
+	// rewrite the note, so it has the new hash and, for new recipes, the UID
+	return s.write(note.path, saved, names)
+}
+
+// syncOrphan syncs a note with a UID Paprika doesn't have
+func (s *vaultSync) syncOrphan(ctx context.Context, note *vaultNote) error {
+	if _, ok := s.state[note.recipe.UID]; !ok {
+		// e.g. copied from another account's vault
+		return s.push(ctx, note, "")
+	}
+
+	if edited, known := s.edited(note); known && !edited {
+		delete(s.state, note.recipe.UID)
+		s.result.Removed = append(s.result.Removed, note.recipe.Name)
+		return s.remove(note.path)
+	}
+	s.conflict(note.recipe.Name, note.path, "deleted in Paprika but edited in the vault")
+	return nil
+}
+
+// newPath returns a path for a new note in dir that no other note has
+func (s *vaultSync) newPath(name, dir string) string {
+	base := safeFileName(name)
+	path := filepath.Join(dir, base+VaultExtension)
+	for n := 2; s.taken[path]; n++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, n, VaultExtension))
+	}
+	s.taken[path] = true
+	return path
+}
+
+func (s *vaultSync) write(path string, recipe *Recipe, categories []string) error {
+	data := EncodeVaultNote(recipe, categories)
+	s.state[recipe.UID] = vaultState{Hash: recipe.Hash, Checksum: checksum(data)}
+	if s.opts.DryRun {
+		return nil
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (s *vaultSync) remove(path string) error {
+	delete(s.taken, path)
+	if s.opts.DryRun {
+		return nil
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,165 @@
+package paprika_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika/paprikatest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultNoteRoundTrip(t *testing.T) {
+	recipe := paprika.Recipe{
+		UID:             "SOUP",
+		Hash:            "abc123",
+		Name:            "Soup",
+		Description:     "A warming soup.",
+		Ingredients:     "1 onion\n2 cups stock",
+		Directions:      "Fry the onion.\n\nAdd the stock.",
+		Notes:           "Freezes well.",
+		NutritionalInfo: "Calories: 120",
+		Servings:        "2",
+		Rating:          4,
+		PrepTime:        "10 min",
+		CookTime:        "20 min",
+		TotalTime:       "30 min",
+		Difficulty:      "Easy",
+		Source:          "Grandma",
+		SourceURL:       "https://example.com/soup",
+		ImageURL:        "https://example.com/soup.jpg",
+		Created:         "2024-01-02 03:04:05",
+		Scale:           "2/1",
+		Photo:           "soup.jpg",
+		PhotoHash:       "def456",
+		PhotoLarge:      "soup-large.jpg",
+		IsPinned:        true,
+		OnFavorites:     true,
+		OnGroceryList:   true,
+		Categories:      []string{"Dinner", "Soups"},
+	}
+
+	note := paprika.EncodeVaultNote(&recipe, recipe.Categories)
+	assert.True(t, strings.HasPrefix(string(note), "---\nuid: SOUP\nhash: abc123\n"))
+	assert.Contains(t, string(note), "\n# Soup\n\n## Description\n\nA warming soup.\n\n## Ingredients\n\n1 onion\n2 cups stock\n")
+
+	back, err := paprika.DecodeVaultNote(note)
+	require.NoError(t, err)
+	assert.Equal(t, recipe, *back)
+
+	// values ending in dashes don't close the front matter
+	recipe.Source, recipe.Notes = "x---", "---"
+	back, err = paprika.DecodeVaultNote(paprika.EncodeVaultNote(&recipe, recipe.Categories))
+	require.NoError(t, err)
+	assert.Equal(t, recipe, *back)
+
+	back, err = paprika.DecodeVaultNote([]byte("---\n---\n\n# Soup\n\n## Ingredients\n\n1 onion\n"))
+	require.NoError(t, err)
+	assert.Equal(t, paprika.Recipe{Name: "Soup", Ingredients: "1 onion"}, *back)
+
+	_, err = paprika.DecodeVaultNote([]byte("# Soup\n"))
+	assert.Error(t, err)
+	_, err = paprika.DecodeVaultNote([]byte("---\nuid: SOUP\n---\n\n## Ingredients\n\n1 onion\n"))
+	assert.Error(t, err)
+}
+
+func TestSyncVault(t *testing.T) {
+	srv := paprikatest.NewServer()
+	defer srv.Close()
+	srv.PutCategory(paprika.Category{UID: "DINNER", Name: "Dinner"})
+	srv.PutRecipe(paprika.Recipe{UID: "SOUP", Name: "Soup", Ingredients: "1 onion", Directions: "Cook.", Categories: []string{"DINNER"}})
+	srv.PutRecipe(paprika.Recipe{UID: "STEW", Name: "Stew", Ingredients: "1 carrot", Directions: "Simmer."})
+	srv.PutRecipe(paprika.Recipe{UID: "OLD", Name: "Old", Directions: "Forget.", InTrash: true})
+
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	ctx := context.Background()
+	dir := t.TempDir()
+	soupPath, stewPath := filepath.Join(dir, "Soup.md"), filepath.Join(dir, "Stew.md")
+
+	// the first sync mirrors the library
+	dryRun, err := client.SyncVault(ctx, dir, paprika.VaultOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Soup", "Stew"}, dryRun.Pulled)
+	assert.NoFileExists(t, soupPath)
+
+	result, err := client.SyncVault(ctx, dir, paprika.VaultOptions{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Soup", "Stew"}, result.Pulled)
+	assert.Empty(t, result.Conflicts)
+	assert.NoFileExists(t, filepath.Join(dir, "Old.md"))
+	soup, err := os.ReadFile(soupPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(soup), "categories:\n    - Dinner\n")
+
+	again, err := client.SyncVault(ctx, dir, paprika.VaultOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, again.Pulled)
+	assert.Empty(t, again.Pushed)
+	assert.Equal(t, 2, again.Unchanged)
+
+	// without the sync state, notes are compared with Paprika
+	require.NoError(t, os.Remove(filepath.Join(dir, ".paprika-sync.json")))
+	again, err = client.SyncVault(ctx, dir, paprika.VaultOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, again.Pushed)
+	assert.Equal(t, 2, again.Unchanged)
+
+	// local edits and new notes are pushed, remote edits are pulled
+	edited := strings.Replace(string(soup), "1 onion", "2 onions", 1)
+	require.NoError(t, os.WriteFile(soupPath, []byte(edited), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Toast.md"), []byte("---\ncategories: [Breakfast]\n---\n\n# Toast\n\n## Directions\n\nToast the bread.\n"), 0o644))
+	stew, _ := srv.Recipe("STEW")
+	stew.Directions, stew.Hash = "Simmer for an hour.", ""
+	srv.PutRecipe(stew)
+
+	recipeLists, categoryLists := srv.Requests("GET", "/api/v2/sync/recipes"), srv.Requests("GET", "/api/v2/sync/categories")
+	result, err = client.SyncVault(ctx, dir, paprika.VaultOptions{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Soup", "Toast"}, result.Pushed)
+	// recipes and categories are listed once per sync, however many notes are pushed
+	assert.Equal(t, recipeLists+1, srv.Requests("GET", "/api/v2/sync/recipes"))
+	assert.Equal(t, categoryLists+1, srv.Requests("GET", "/api/v2/sync/categories"))
+	assert.Equal(t, []string{"Stew"}, result.Pulled)
+	assert.Empty(t, result.Conflicts)
+
+	remoteSoup, _ := srv.Recipe("SOUP")
+	assert.Equal(t, "2 onions", remoteSoup.Ingredients)
+	assert.Equal(t, []string{"DINNER"}, remoteSoup.Categories)
+	assert.Len(t, srv.Recipes(), 4)
+	toast, err := os.ReadFile(filepath.Join(dir, "Toast.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(toast), "uid: ")
+	stewNote, err := os.ReadFile(stewPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(stewNote), "Simmer for an hour.")
+
+	// edits on both sides are a conflict and neither side changes
+	soup, err = os.ReadFile(soupPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(soupPath, []byte(strings.Replace(string(soup), "Cook.", "Cook slowly.", 1)), 0o644))
+	remoteSoup.Directions, remoteSoup.Hash = "Cook quickly.", ""
+	srv.PutRecipe(remoteSoup)
+
+	result, err = client.SyncVault(ctx, dir, paprika.VaultOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "Soup", result.Conflicts[0].Name)
+	assert.Equal(t, soupPath, result.Conflicts[0].Path)
+	remoteSoup, _ = srv.Recipe("SOUP")
+	assert.Equal(t, "Cook quickly.", remoteSoup.Directions)
+	soup, err = os.ReadFile(soupPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(soup), "Cook slowly.")
+
+	// recipes deleted in Paprika are removed from the vault
+	srv.RemoveRecipe("STEW")
+	result, err = client.SyncVault(ctx, dir, paprika.VaultOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Stew"}, result.Removed)
+	assert.NoFileExists(t, stewPath)
+}
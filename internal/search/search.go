@@ -0,0 +1,292 @@
+// Package search implements an in-memory full-text index over a recipe library
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+)
+
+// field is a part of a recipe that is indexed, along with how much a match in it counts
+type field struct {
+	name   string
+	weight float64
+	text   func(r *paprika.Recipe, categories []string) string
+}
+
+var fields = []field{
+	{"name", 5, func(r *paprika.Recipe, _ []string) string { return r.Name }},
+	{"categories", 3, func(_ *paprika.Recipe, categories []string) string { return strings.Join(categories, "\n") }},
+	{"ingredients", 2, func(r *paprika.Recipe, _ []string) string { return r.Ingredients }},
+	{"description", 1, func(r *paprika.Recipe, _ []string) string { return r.Description }},
+	{"notes", 1, func(r *paprika.Recipe, _ []string) string { return r.Notes }},
+	{"directions", 1, func(r *paprika.Recipe, _ []string) string { return r.Directions }},
+	{"source", 1, func(r *paprika.Recipe, _ []string) string { return r.Source }},
+}
+
+// document is an indexed recipe
+type document struct {
+	recipe     *paprika.Recipe
+	categories []string
+	terms      map[string]float64
+}
+
+// Index is an inverted index of recipes. It is safe for concurrent use.
+type Index struct {
+	mu   sync.RWMutex
+	docs map[string]*document
+	// postings maps a term to the UIDs of the recipes containing it and the weight of the term in each
+	postings map[string]map[string]float64
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// Add indexes a recipe, replacing any earlier version. categories are the names of the recipe's categories.
+func (i *Index) Add(recipe *paprika.Recipe, categories []string) {
+	doc := &document{recipe: recipe, categories: categories, terms: make(map[string]float64)}
+	for _, f := range fields {
+		for _, term := range tokenize(f.text(recipe, categories)) {
+			doc.terms[term] += f.weight
+		}
+	}
+
+	uid := strings.ToUpper(recipe.UID)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(uid)
+	i.docs[uid] = doc
+	for term, weight := range doc.terms {
+		if i.postings[term] == nil {
+			i.postings[term] = make(map[string]float64)
+		}
+		i.postings[term][uid] = weight
+	}
+}
+
+// Remove drops a recipe from the index
+func (i *Index) Remove(uid string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(strings.ToUpper(uid))
+}
+
+func (i *Index) remove(uid string) {
+	doc, ok := i.docs[uid]
+	if !ok {
+		return
+	}
+
+	for term := range doc.terms {
+		delete(i.postings[term], uid)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.docs, uid)
+}
+
+// Len returns the number of indexed recipes
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// Query describes a search. The zero value matches every recipe.
+type Query struct {
+	// Text is matched against the recipe's words; every word must match
+	Text string
+	// MinRating only matches recipes rated at least this many stars
+	MinRating int
+	// Favorites only matches favourite recipes
+	Favorites bool
+	// Pinned only matches pinned recipes
+	Pinned bool
+	// MaxTotalTime only matches recipes that take at most this long. Recipes without a known total time don't match.
+	MaxTotalTime time.Duration
+	// Difficulty only matches recipes with this difficulty, ignoring case
+	Difficulty string
+	// Limit caps the number of results; zero means no limit
+	Limit int
+}
+
+// Result is a recipe matching a query
+type Result struct {
+	Recipe *paprika.Recipe
+	// Score ranks the result; higher is better. Results of queries without text all score zero.
+	Score float64
+	// Snippet is the line of the recipe that best shows why it matched, if any
+	Snippet string
+}
+
+// Search returns the recipes matching a query, best match first.
+// Queries without text are sorted by name instead.
+func (i *Index) Search(q Query) []Result {
+	terms := tokenize(q.Text)
+	if len(terms) == 0 && strings.TrimSpace(q.Text) != "" {
+		// text without any words, like "!!!", can't match anything
+		return nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	scores := i.score(terms)
+
+	var results []Result
+	for uid, doc := range i.docs {
+		if !q.matches(doc.recipe) {
+			continue
+		}
+
+		score, ok := scores[uid]
+		if len(terms) > 0 && !ok {
+			continue
+		}
+		results = append(results, Result{Recipe: doc.recipe, Score: score, Snippet: snippet(doc, terms)})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		if results[a].Recipe.Name != results[b].Recipe.Name {
+			return results[a].Recipe.Name < results[b].Recipe.Name
+		}
+		return results[a].Recipe.UID < results[b].Recipe.UID
+	})
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results
+}
+
+// score returns the score of every recipe that matches all terms. A recipe scores the weight
+// of each term in it, with rare terms boosted. A term also matches longer words it is a prefix of,
+// at half the weight.
+func (i *Index) score(terms []string) map[string]float64 {
+	var scores map[string]float64
+	for _, term := range terms {
+		best := make(map[string]float64)
+		for indexed, postings := range i.postings {
+			factor := 1.0
+			if indexed != term {
+				if len(term) < 3 || !strings.HasPrefix(indexed, term) {
+					continue
+				}
+				factor = 0.5
+			}
+
+			idf := math.Log(1 + float64(len(i.docs))/float64(len(postings)))
+			for uid, weight := range postings {
+				best[uid] = math.Max(best[uid], weight*factor*idf)
+			}
+		}
+
+		if scores == nil {
+			scores = best
+			continue
+		}
+		for uid := range scores {
+			if _, ok := best[uid]; !ok {
+				delete(scores, uid)
+				continue
+			}
+			scores[uid] += best[uid]
+		}
+	}
+	return scores
+}
+
+func (q Query) matches(r *paprika.Recipe) bool {
+	if r.Rating < q.MinRating {
+		return false
+	}
+	if q.Favorites && !r.OnFavorites {
+		return false
+	}
+	if q.Pinned && !r.IsPinned {
+		return false
+	}
+	if q.Difficulty != "" && !strings.EqualFold(strings.TrimSpace(r.Difficulty), strings.TrimSpace(q.Difficulty)) {
+		return false
+	}
+	if q.MaxTotalTime > 0 {
+		total, ok := r.TotalDuration()
+		if !ok || total > q.MaxTotalTime {
+			return false
+		}
+	}
+	return true
+}
+
+// snippet returns the first line of the recipe's text that contains one of the terms
+func snippet(doc *document, terms []string) string {
+	if len(terms) == 0 {
+		return ""
+	}
+
+	for _, f := range fields {
+		if f.name == "name" {
+			continue
+		}
+		for _, line := range strings.Split(f.text(doc.recipe, doc.categories), "\n") {
+			for _, word := range tokenize(line) {
+				for _, term := range terms {
+					if strings.HasPrefix(word, term) {
+						return truncate(strings.TrimSpace(line), 160)
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
+
+// tokenize splits text into lowercase words, folding simple plurals so "tomatoes" matches "tomato"
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, singular(word))
+	}
+	return terms
+}
+
+func singular(word string) string {
+	switch {
+	case len(word) <= 3:
+		return word
+	case strings.HasSuffix(word, "oes"), strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
@@ -0,0 +1,107 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/soggycactus/paprika-3-mcp/internal/paprika"
+	"github.com/soggycactus/paprika-3-mcp/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func uids(results []search.Result) []string {
+	var uids []string
+	for _, r := range results {
+		uids = append(uids, r.Recipe.UID)
+	}
+	return uids
+}
+
+func newIndex() *search.Index {
+	index := search.NewIndex()
+	index.Add(&paprika.Recipe{
+		UID:         "SOUP",
+		Name:        "Tomato Soup",
+		Ingredients: "6 tomatoes\n1 onion\n2 cups stock",
+		Directions:  "Simmer the tomatoes with the onion.\nBlend.",
+		Rating:      4,
+		TotalTime:   "45 mins",
+		Difficulty:  "Easy",
+	}, []string{"Soups"})
+	index.Add(&paprika.Recipe{
+		UID:         "SALAD",
+		Name:        "Caprese Salad",
+		Ingredients: "2 tomatoes\n1 ball mozzarella\nbasil",
+		Rating:      5,
+		OnFavorites: true,
+		PrepTime:    "10 min",
+	}, []string{"Salads", "Vegetarian"})
+	index.Add(&paprika.Recipe{
+		UID:         "STEW",
+		Name:        "Beef Stew",
+		Ingredients: "1 kg beef\n2 onions\n3 carrots",
+		Notes:       "From grandma",
+		Source:      "Grandma's cookbook",
+		TotalTime:   "2 hrs 30 mins",
+		IsPinned:    true,
+	}, nil)
+	return index
+}
+
+func TestSearchRanksNameMatchesFirst(t *testing.T) {
+	results := newIndex().Search(search.Query{Text: "tomato"})
+	assert.Equal(t, []string{"SOUP", "SALAD"}, uids(results))
+	assert.Equal(t, "6 tomatoes", results[0].Snippet)
+	assert.Equal(t, "2 tomatoes", results[1].Snippet)
+}
+
+func TestSearchRequiresEveryWord(t *testing.T) {
+	index := newIndex()
+	assert.Equal(t, []string{"SOUP", "STEW"}, uids(index.Search(search.Query{Text: "onions"})))
+	assert.Equal(t, []string{"STEW"}, uids(index.Search(search.Query{Text: "onion carrot"})))
+	assert.Empty(t, index.Search(search.Query{Text: "onion mozzarella"}))
+}
+
+func TestSearchMatchesPrefixesCategoriesAndSource(t *testing.T) {
+	index := newIndex()
+	assert.Equal(t, []string{"SALAD"}, uids(index.Search(search.Query{Text: "mozz"})))
+	assert.Equal(t, []string{"SALAD"}, uids(index.Search(search.Query{Text: "vegetarian"})))
+	assert.Equal(t, []string{"STEW"}, uids(index.Search(search.Query{Text: "grandma cookbook"})))
+}
+
+func TestSearchFilters(t *testing.T) {
+	index := newIndex()
+
+	assert.Equal(t, []string{"STEW", "SALAD", "SOUP"}, uids(index.Search(search.Query{})), "sorted by name")
+	assert.Equal(t, []string{"SALAD", "SOUP"}, uids(index.Search(search.Query{MinRating: 4})))
+	assert.Equal(t, []string{"SALAD"}, uids(index.Search(search.Query{Favorites: true})))
+	assert.Equal(t, []string{"STEW"}, uids(index.Search(search.Query{Pinned: true})))
+	assert.Equal(t, []string{"SOUP"}, uids(index.Search(search.Query{Difficulty: "easy"})))
+	assert.Equal(t, []string{"SALAD", "SOUP"}, uids(index.Search(search.Query{MaxTotalTime: time.Hour})))
+	assert.Equal(t, []string{"SALAD"}, uids(index.Search(search.Query{Text: "tomato", MaxTotalTime: 30 * time.Minute})))
+	assert.Len(t, index.Search(search.Query{Limit: 2}), 2)
+	assert.Empty(t, index.Search(search.Query{Text: "!!!"}), "text without words matches nothing")
+}
+
+func TestIndexSearchISODuration(t *testing.T) {
+	index := search.NewIndex()
+	index.Add(&paprika.Recipe{UID: "BREAD", Name: "Bread", TotalTime: "PT3H"}, nil)
+	index.Add(&paprika.Recipe{UID: "TOAST", Name: "Toast", TotalTime: "PT5M"}, nil)
+
+	assert.Equal(t, []string{"TOAST"}, uids(index.Search(search.Query{MaxTotalTime: time.Hour})))
+}
+
+func TestIndexReplaceAndRemove(t *testing.T) {
+	index := newIndex()
+	require.Equal(t, 3, index.Len())
+
+	index.Add(&paprika.Recipe{UID: "soup", Name: "Pumpkin Soup", Ingredients: "1 pumpkin"}, nil)
+	assert.Equal(t, 3, index.Len())
+	assert.Equal(t, []string{"SALAD"}, uids(index.Search(search.Query{Text: "tomato"})))
+	assert.Equal(t, []string{"soup"}, uids(index.Search(search.Query{Text: "pumpkin"})))
+
+	index.Remove("SOUP")
+	assert.Equal(t, 2, index.Len())
+	assert.Empty(t, index.Search(search.Query{Text: "pumpkin"}))
+}